@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// getEvents streams the configuration change events as Server-Sent Events.
+func (h Handler) getEvents(rw http.ResponseWriter, request *http.Request) {
+	if h.events == nil {
+		writeError(rw, "configuration events are not available", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		writeError(rw, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.FromContext(request.Context()).Error(err)
+				continue
+			}
+
+			if _, err = fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				log.FromContext(request.Context()).Debugf("Unable to write configuration event: %v", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
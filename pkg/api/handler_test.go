@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
 	"flag"
 	"io"
@@ -8,12 +9,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/events"
 )
 
 var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
@@ -281,3 +284,85 @@ func TestHandler_GetMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_Events(t *testing.T) {
+	bus := events.NewBus(10)
+
+	handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, bus)(&runtime.Configuration{})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/events")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	bus.ListenConfiguration(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Services: map[string]*dynamic.Service{
+				"foo@myprovider": {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+			},
+		},
+	})
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: added\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"kind":"service","name":"foo@myprovider"`)
+}
+
+func TestHandler_Events_dropped(t *testing.T) {
+	bus := events.NewBus(1)
+
+	handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, bus)(&runtime.Configuration{})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/events")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	// The events are published faster than the stream sends them, so some are dropped.
+	event := events.Event{Type: events.Added, Kind: events.KindService, Name: "foo@myprovider"}
+	require.Eventually(t, func() bool {
+		bus.Publish(event, event, event, event, event)
+		return bus.Dropped() > 0
+	}, time.Second, time.Millisecond)
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		if line == "event: dropped\n" {
+			break
+		}
+
+		// The Dropped event is sent once the buffer has room again.
+		bus.Publish(event)
+	}
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"type":"dropped"`)
+	assert.Contains(t, line, `"count":`)
+}
+
+func TestHandler_Events_notAvailable(t *testing.T) {
+	handler := NewBuilder(static.Configuration{API: &static.API{}, Global: &static.Global{}}, nil)(&runtime.Configuration{})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/events")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -157,6 +157,10 @@ type ServersLoadBalancer struct {
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
 	ServersTransport   string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	// WarmUpConnections is the number of idle connections to pre-establish to each server
+	// once the first round of health checks is over, so that the first requests do not pay the connection cost.
+	// It requires a health check path, on which the warm-up requests are sent.
+	WarmUpConnections int `json:"warmUpConnections,omitempty" toml:"warmUpConnections,omitempty" yaml:"warmUpConnections,omitempty" export:"true"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -224,6 +228,12 @@ type ServerHealthCheck struct {
 	Hostname        string            `json:"hostname,omitempty" toml:"hostname,omitempty" yaml:"hostname,omitempty"`
 	FollowRedirects *bool             `json:"followRedirects" toml:"followRedirects" yaml:"followRedirects" export:"true"`
 	Headers         map[string]string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+	// DrainDuration is the maximum duration a server failing its checks keeps serving its in-flight requests before being reported as down.
+	DrainDuration ptypes.Duration `json:"drainDuration,omitempty" toml:"drainDuration,omitempty" yaml:"drainDuration,omitempty" export:"true"`
+	// PassiveWindow is the number of the last responses of a server over which the passive health check computes its error ratio.
+	PassiveWindow int `json:"passiveWindow,omitempty" toml:"passiveWindow,omitempty" yaml:"passiveWindow,omitempty" export:"true"`
+	// PassiveErrorRatio is the ratio of 5xx responses above which the passive health check removes a server.
+	PassiveErrorRatio float64 `json:"passiveErrorRatio,omitempty" toml:"passiveErrorRatio,omitempty" yaml:"passiveErrorRatio,omitempty" export:"true"`
 }
 
 // SetDefaults Default values for a HealthCheck.
@@ -27,6 +27,7 @@ type Middleware struct {
 	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty" export:"true"`
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty" export:"true"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty" export:"true"`
+	ExternalOPA       *ExternalOPA       `json:"externalOPA,omitempty" toml:"externalOPA,omitempty" yaml:"externalOPA,omitempty" export:"true"`
 	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty" export:"true"`
 	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty" export:"true"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
@@ -145,6 +146,9 @@ type CircuitBreaker struct {
 	FallbackDuration ptypes.Duration `json:"fallbackDuration,omitempty" toml:"fallbackDuration,omitempty" yaml:"fallbackDuration,omitempty" export:"true"`
 	// RecoveryDuration is the duration for which the circuit breaker will try to recover (as soon as it is in recovering state).
 	RecoveryDuration ptypes.Duration `json:"recoveryDuration,omitempty" toml:"recoveryDuration,omitempty" yaml:"recoveryDuration,omitempty" export:"true"`
+	// HealthCheckService is the name of the service which must have a healthy server before the circuit breaker tries to recover (from a tripped state).
+	// It must be a load-balancer service with a health check.
+	HealthCheckService string `json:"healthCheckService,omitempty" toml:"healthCheckService,omitempty" yaml:"healthCheckService,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -230,6 +234,28 @@ type ForwardAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// ExternalOPA holds the ExternalOPA middleware configuration.
+// This middleware authorizes the requests by querying a decision of an external Open Policy Agent server,
+// which evaluates the policies: they are not evaluated by Traefik.
+type ExternalOPA struct {
+	// Address defines the address of the OPA server.
+	Address string `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	// Decision defines the path of the policy decision to query (e.g. traefik/authz/allow).
+	Decision string `json:"decision,omitempty" toml:"decision,omitempty" yaml:"decision,omitempty" export:"true"`
+	// PolicyFile defines the path of a Rego policy file to push to the OPA server.
+	// The policy is pushed again whenever the file changes.
+	PolicyFile string `json:"policyFile,omitempty" toml:"policyFile,omitempty" yaml:"policyFile,omitempty"`
+	// InputHeaders defines the list of the request headers sent to the OPA server in the input of the decision.
+	// If not set or empty then no request header is sent.
+	InputHeaders []string `json:"inputHeaders,omitempty" toml:"inputHeaders,omitempty" yaml:"inputHeaders,omitempty" export:"true"`
+	// ReasonHeader defines the name of the response header conveying the reason of a denial.
+	ReasonHeader string `json:"reasonHeader,omitempty" toml:"reasonHeader,omitempty" yaml:"reasonHeader,omitempty" export:"true"`
+	// Timeout defines the timeout of the requests to the OPA server, 30s when zero.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Headers holds the headers middleware configuration.
 // This middleware manages the requests and responses headers.
 // More info: https://doc.traefik.io/traefik/v2.9/middlewares/http/headers/#customrequestheaders
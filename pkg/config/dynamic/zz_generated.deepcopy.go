@@ -285,6 +285,27 @@ func (in *ErrorPage) DeepCopy() *ErrorPage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalOPA) DeepCopyInto(out *ExternalOPA) {
+	*out = *in
+	if in.InputHeaders != nil {
+		in, out := &in.InputHeaders, &out.InputHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalOPA.
+func (in *ExternalOPA) DeepCopy() *ExternalOPA {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalOPA)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Failover) DeepCopyInto(out *Failover) {
 	*out = *in
@@ -720,6 +741,11 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 		*out = new(ForwardAuth)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalOPA != nil {
+		in, out := &in.ExternalOPA, &out.ExternalOPA
+		*out = new(ExternalOPA)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.InFlightReq != nil {
 		in, out := &in.InFlightReq, &out.InFlightReq
 		*out = new(InFlightReq)
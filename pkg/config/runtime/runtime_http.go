@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/log"
@@ -142,7 +143,27 @@ type ServiceInfo struct {
 	UsedBy []string `json:"usedBy,omitempty"` // list of routers using that service
 
 	serverStatusMu sync.RWMutex
-	serverStatus   map[string]string // keyed by server URL
+	serverStatus   map[string]string      // keyed by server URL
+	serverChecks   map[string]ServerCheck // keyed by server URL
+}
+
+// ServerCheck is the result of a health check of a server.
+type ServerCheck struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	// StatusCode is the status code of the response which failed the check, if any.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Reason is the category of the failure, e.g. dns, connection refused, tls, timeout, or status.
+	Reason string `json:"reason,omitempty"`
+	// Address is the address dialed by an HTTP check, and Host the Host header it sent.
+	Address string `json:"address,omitempty"`
+	Host    string `json:"host,omitempty"`
+}
+
+// ServerStatus is the status of a server, along with the result of its last health check.
+type ServerStatus struct {
+	Status    string       `json:"status,omitempty"`
+	LastCheck *ServerCheck `json:"lastCheck,omitempty"`
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -178,6 +199,42 @@ func (s *ServiceInfo) UpdateServerStatus(server, status string) {
 	s.serverStatus[server] = status
 }
 
+// UpdateServerCheck sets the result of the last health check of the server in the ServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) UpdateServerCheck(server string, check ServerCheck) {
+	s.serverStatusMu.Lock()
+	defer s.serverStatusMu.Unlock()
+
+	if s.serverChecks == nil {
+		s.serverChecks = make(map[string]ServerCheck)
+	}
+	s.serverChecks[server] = check
+}
+
+// GetAllServerStatus returns the statuses of all the servers in ServiceInfo,
+// along with the results of their last health check.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) GetAllServerStatus() map[string]ServerStatus {
+	s.serverStatusMu.RLock()
+	defer s.serverStatusMu.RUnlock()
+
+	if len(s.serverStatus) == 0 && len(s.serverChecks) == 0 {
+		return nil
+	}
+
+	allStatus := make(map[string]ServerStatus, len(s.serverStatus))
+	for k, v := range s.serverStatus {
+		allStatus[k] = ServerStatus{Status: v}
+	}
+	for k, v := range s.serverChecks {
+		check := v
+		status := allStatus[k]
+		status.LastCheck = &check
+		allStatus[k] = status
+	}
+	return allStatus
+}
+
 // GetAllStatus returns all the statuses of all the servers in ServiceInfo.
 // It is the responsibility of the caller to check that s is not nil.
 func (s *ServiceInfo) GetAllStatus() map[string]string {
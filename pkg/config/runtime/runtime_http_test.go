@@ -3,6 +3,7 @@ package runtime
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
@@ -217,3 +218,36 @@ func TestGetRoutersByEntryPoints(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceInfo_GetAllServerStatus(t *testing.T) {
+	si := &ServiceInfo{}
+	assert.Nil(t, si.GetAllServerStatus())
+
+	si.UpdateServerStatus("http://127.0.0.1", "UP")
+	si.UpdateServerStatus("http://127.0.0.2", "DOWN")
+
+	checkedAt := time.Now()
+	si.UpdateServerCheck("http://127.0.0.2", ServerCheck{
+		CheckedAt:  checkedAt,
+		StatusCode: 503,
+		Error:      "received error status code: 503",
+	})
+
+	assert.Equal(t, map[string]ServerStatus{
+		"http://127.0.0.1": {Status: "UP"},
+		"http://127.0.0.2": {
+			Status: "DOWN",
+			LastCheck: &ServerCheck{
+				CheckedAt:  checkedAt,
+				StatusCode: 503,
+				Error:      "received error status code: 503",
+			},
+		},
+	}, si.GetAllServerStatus())
+
+	// The checks do not change the statuses.
+	assert.Equal(t, map[string]string{
+		"http://127.0.0.1": "UP",
+		"http://127.0.0.2": "DOWN",
+	}, si.GetAllStatus())
+}
@@ -0,0 +1,315 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/tls"
+	"golang.org/x/time/rate"
+)
+
+const defaultBufferSize = 100
+
+// dropLogPeriod is the minimum period between two warnings about dropped events.
+const dropLogPeriod = time.Minute
+
+// Type is the type of change an Event describes.
+type Type string
+
+// Event types.
+const (
+	Added   Type = "added"
+	Removed Type = "removed"
+	Updated Type = "updated"
+	// Dropped reports to a subscriber the number of events it missed, in the Count field.
+	Dropped Type = "dropped"
+)
+
+// Kinds of configuration elements.
+const (
+	KindRouter         = "router"
+	KindService        = "service"
+	KindMiddleware     = "middleware"
+	KindTCPRouter      = "tcpRouter"
+	KindTCPService     = "tcpService"
+	KindTCPMiddleware  = "tcpMiddleware"
+	KindUDPRouter      = "udpRouter"
+	KindUDPService     = "udpService"
+	KindTLSCertificate = "tlsCertificate"
+)
+
+// Event describes a change of an element of the dynamic configuration.
+type Event struct {
+	Type  Type      `json:"type"`
+	Kind  string    `json:"kind,omitempty"`
+	Name  string    `json:"name,omitempty"`
+	Time  time.Time `json:"time"`
+	Count uint64    `json:"count,omitempty"`
+}
+
+// subscriber is a subscription to the Bus.
+type subscriber struct {
+	ch chan Event
+	// dropped is the number of events dropped since the last Dropped event sent to the subscriber.
+	dropped uint64
+}
+
+// Bus publishes the configuration change events to its subscribers.
+// Publishing never blocks: the events which do not fit in the buffer of a subscriber are dropped,
+// and the subscriber receives a Dropped event once its buffer has room again.
+type Bus struct {
+	bufferSize int
+
+	// mu serializes the publications, so that the events are received in order, and guards the fields below.
+	mu          sync.Mutex
+	subscribers map[chan Event]*subscriber
+	dropped     uint64
+	dropLog     *rate.Limiter
+
+	// previous is the last configuration received by ListenConfiguration.
+	previous dynamic.Configuration
+	// previousCertificates are the fingerprints of the certificates of the previous configuration,
+	// computed on its reception, as the certificate files may have been renewed in place since.
+	previousCertificates map[string]string
+}
+
+// NewBus creates a new Bus.
+// The bufferSize is the number of events each subscriber can lag behind before events get dropped.
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Bus{
+		bufferSize:  bufferSize,
+		subscribers: make(map[chan Event]*subscriber),
+		dropLog:     rate.NewLimiter(rate.Every(dropLogPeriod), 1),
+	}
+}
+
+// Subscribe returns a channel receiving the published events,
+// and the function to call to unsubscribe.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, b.bufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = &subscriber{ch: ch}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish sends the given events to all the subscribers.
+func (b *Bus) Publish(events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.publish(events)
+}
+
+// publish sends the given events to all the subscribers. b.mu must be held.
+func (b *Bus) publish(events []Event) {
+	var dropped uint64
+	for _, sub := range b.subscribers {
+		dropped += sub.publish(events)
+	}
+
+	if dropped == 0 {
+		return
+	}
+
+	b.dropped += dropped
+	if b.dropLog.Allow() {
+		log.WithoutContext().Warnf("Dropped %d configuration events for slow subscribers (%d in total)", dropped, b.dropped)
+	}
+}
+
+// publish sends the given events to the subscriber, preceded by a Dropped event if it missed some,
+// and returns the number of events dropped.
+func (s *subscriber) publish(events []Event) uint64 {
+	if s.dropped > 0 {
+		select {
+		case s.ch <- Event{Type: Dropped, Time: time.Now(), Count: s.dropped}:
+			s.dropped = 0
+		default:
+			// The events cannot be sent before the Dropped event, or the subscriber would not know where the gap is.
+			return s.drop(events)
+		}
+	}
+
+	for i, event := range events {
+		select {
+		case s.ch <- event:
+		default:
+			// Once an event is dropped, so are the following ones, for the gap to be reported where it is.
+			return s.drop(events[i:])
+		}
+	}
+
+	return 0
+}
+
+// drop records the given events as dropped, and returns their number.
+func (s *subscriber) drop(events []Event) uint64 {
+	dropped := uint64(len(events))
+	s.dropped += dropped
+	return dropped
+}
+
+// Dropped returns the number of events that were dropped because of slow subscribers.
+func (b *Bus) Dropped() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.dropped
+}
+
+// ListenConfiguration publishes the changes between the given configuration and the previous one.
+// It is meant to be registered as a configuration watcher listener.
+func (b *Bus) ListenConfiguration(conf dynamic.Configuration) {
+	// DeepCopy is necessary because the configuration is shared with the other listeners.
+	current := *conf.DeepCopy()
+	currentCertificates := certificates(current.TLS)
+
+	// The lock is held until the events are published,
+	// so that the events of successive configurations are published in the order of the configurations.
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	previous, previousCertificates := b.previous, b.previousCertificates
+	b.previous, b.previousCertificates = current, currentCertificates
+
+	now := time.Now()
+	events := diffElements(previous, current, now)
+	events = append(events, diff(KindTLSCertificate, previousCertificates, currentCertificates, now)...)
+	if len(events) == 0 {
+		return
+	}
+
+	log.WithoutContext().Debugf("Publishing %d configuration events", len(events))
+	b.publish(events)
+}
+
+// Diff returns the events describing the changes from the previous configuration to the current one.
+func Diff(previous, current dynamic.Configuration, now time.Time) []Event {
+	events := diffElements(previous, current, now)
+	return append(events, diff(KindTLSCertificate, certificates(previous.TLS), certificates(current.TLS), now)...)
+}
+
+// diffElements returns the events describing the changes of the routers, services, and middlewares.
+func diffElements(previous, current dynamic.Configuration, now time.Time) []Event {
+	var events []Event
+
+	previousHTTP, currentHTTP := previous.HTTP, current.HTTP
+	if previousHTTP == nil {
+		previousHTTP = &dynamic.HTTPConfiguration{}
+	}
+	if currentHTTP == nil {
+		currentHTTP = &dynamic.HTTPConfiguration{}
+	}
+	events = append(events, diff(KindRouter, previousHTTP.Routers, currentHTTP.Routers, now)...)
+	events = append(events, diff(KindService, previousHTTP.Services, currentHTTP.Services, now)...)
+	events = append(events, diff(KindMiddleware, previousHTTP.Middlewares, currentHTTP.Middlewares, now)...)
+
+	previousTCP, currentTCP := previous.TCP, current.TCP
+	if previousTCP == nil {
+		previousTCP = &dynamic.TCPConfiguration{}
+	}
+	if currentTCP == nil {
+		currentTCP = &dynamic.TCPConfiguration{}
+	}
+	events = append(events, diff(KindTCPRouter, previousTCP.Routers, currentTCP.Routers, now)...)
+	events = append(events, diff(KindTCPService, previousTCP.Services, currentTCP.Services, now)...)
+	events = append(events, diff(KindTCPMiddleware, previousTCP.Middlewares, currentTCP.Middlewares, now)...)
+
+	previousUDP, currentUDP := previous.UDP, current.UDP
+	if previousUDP == nil {
+		previousUDP = &dynamic.UDPConfiguration{}
+	}
+	if currentUDP == nil {
+		currentUDP = &dynamic.UDPConfiguration{}
+	}
+	events = append(events, diff(KindUDPRouter, previousUDP.Routers, currentUDP.Routers, now)...)
+	events = append(events, diff(KindUDPService, previousUDP.Services, currentUDP.Services, now)...)
+
+	return events
+}
+
+// certificates returns the fingerprints of the TLS certificates indexed by their name,
+// so that a certificate renewed under the same name, e.g. in the same file, shows up as updated.
+func certificates(conf *dynamic.TLSConfiguration) map[string]string {
+	if conf == nil {
+		return nil
+	}
+
+	certs := make(map[string]string, len(conf.Certificates))
+	for _, cert := range conf.Certificates {
+		if cert == nil {
+			continue
+		}
+
+		certs[cert.Certificate.GetTruncatedCertificateName()] = fingerprint(cert.Certificate.CertFile)
+	}
+
+	return certs
+}
+
+// fingerprint returns the SHA-256 fingerprint of the content of the given certificate,
+// or of the certificate itself if its file cannot be read.
+func fingerprint(cert tls.FileOrContent) string {
+	content, err := cert.Read()
+	if err != nil {
+		content = []byte(cert)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func diff[T any](kind string, previous, current map[string]T, now time.Time) []Event {
+	var events []Event
+
+	for _, name := range sortedKeys(previous) {
+		previousElement := previous[name]
+		currentElement, ok := current[name]
+		if !ok {
+			events = append(events, Event{Type: Removed, Kind: kind, Name: name, Time: now})
+			continue
+		}
+
+		if !reflect.DeepEqual(previousElement, currentElement) {
+			events = append(events, Event{Type: Updated, Kind: kind, Name: name, Time: now})
+		}
+	}
+
+	for _, name := range sortedKeys(current) {
+		if _, ok := previous[name]; !ok {
+			events = append(events, Event{Type: Added, Kind: kind, Name: name, Time: now})
+		}
+	}
+
+	return events
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,237 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/tls"
+)
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+
+	previous := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo": {Rule: "Host(`foo.localhost`)", Service: "foo"},
+				"bar": {Rule: "Host(`bar.localhost`)", Service: "foo"},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://127.0.0.1:8080"}}}},
+			},
+		},
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{{Certificate: tls.Certificate{CertFile: "/old.crt"}}},
+		},
+	}
+
+	current := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo": {Rule: "Host(`foo.localhost`)", Service: "foo"},
+				"baz": {Rule: "Host(`baz.localhost`)", Service: "foo"},
+			},
+			Services: map[string]*dynamic.Service{
+				"foo": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://127.0.0.1:8081"}}}},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"foo": {Rule: "HostSNI(`*`)", Service: "foo"},
+			},
+		},
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{{Certificate: tls.Certificate{CertFile: "/new.crt"}}},
+		},
+	}
+
+	expected := []Event{
+		{Type: Removed, Kind: KindRouter, Name: "bar", Time: now},
+		{Type: Added, Kind: KindRouter, Name: "baz", Time: now},
+		{Type: Updated, Kind: KindService, Name: "foo", Time: now},
+		{Type: Added, Kind: KindTCPRouter, Name: "foo", Time: now},
+		{Type: Removed, Kind: KindTLSCertificate, Name: "/old.crt", Time: now},
+		{Type: Added, Kind: KindTLSCertificate, Name: "/new.crt", Time: now},
+	}
+
+	assert.Equal(t, expected, Diff(previous, current, now))
+	assert.Empty(t, Diff(current, current, now))
+}
+
+func TestBus_ListenConfiguration(t *testing.T) {
+	bus := NewBus(10)
+
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	bus.ListenConfiguration(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo": {Rule: "Host(`foo.localhost`)", Service: "foo"},
+			},
+		},
+	})
+
+	event := <-events
+	assert.Equal(t, Added, event.Type)
+	assert.Equal(t, KindRouter, event.Kind)
+	assert.Equal(t, "foo", event.Name)
+
+	bus.ListenConfiguration(dynamic.Configuration{})
+
+	event = <-events
+	assert.Equal(t, Removed, event.Type)
+	assert.Equal(t, KindRouter, event.Kind)
+	assert.Equal(t, "foo", event.Name)
+
+	assert.Empty(t, events)
+}
+
+func TestBus_ListenConfiguration_renewedCertificate(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "cert.crt")
+	require.NoError(t, os.WriteFile(certFile, []byte("certificate"), 0o600))
+
+	conf := dynamic.Configuration{
+		TLS: &dynamic.TLSConfiguration{
+			Certificates: []*tls.CertAndStores{{Certificate: tls.Certificate{CertFile: tls.FileOrContent(certFile)}}},
+		},
+	}
+
+	bus := NewBus(10)
+
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	bus.ListenConfiguration(conf)
+
+	event := <-events
+	assert.Equal(t, Added, event.Type)
+	assert.Equal(t, KindTLSCertificate, event.Kind)
+	assert.Equal(t, certFile, event.Name)
+
+	// The certificate is renewed in place, under the same name.
+	require.NoError(t, os.WriteFile(certFile, []byte("renewed certificate"), 0o600))
+	bus.ListenConfiguration(conf)
+
+	event = <-events
+	assert.Equal(t, Updated, event.Type)
+	assert.Equal(t, KindTLSCertificate, event.Kind)
+	assert.Equal(t, certFile, event.Name)
+
+	bus.ListenConfiguration(conf)
+	assert.Empty(t, events)
+}
+
+func TestBus_Publish_dropsWhenFull(t *testing.T) {
+	bus := NewBus(1)
+
+	events, unsubscribe := bus.Subscribe()
+
+	bus.Publish(Event{Name: "foo"}, Event{Name: "bar"})
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, uint64(1), bus.Dropped())
+
+	unsubscribe()
+	unsubscribe()
+
+	bus.Publish(Event{Name: "baz"})
+	assert.Equal(t, uint64(1), bus.Dropped())
+}
+
+func TestBus_Publish_reportsDropped(t *testing.T) {
+	bus := NewBus(2)
+
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	bus.Publish(Event{Name: "foo"}, Event{Name: "bar"}, Event{Name: "baz"})
+	assert.Equal(t, uint64(1), bus.Dropped())
+
+	// The buffer is still full: the Dropped event cannot be sent, nor can the events following it.
+	bus.Publish(Event{Name: "qux"})
+	assert.Equal(t, uint64(2), bus.Dropped())
+
+	assert.Equal(t, "foo", (<-events).Name)
+	assert.Equal(t, "bar", (<-events).Name)
+
+	bus.Publish(Event{Name: "quux"})
+
+	event := <-events
+	assert.Equal(t, Dropped, event.Type)
+	assert.Equal(t, uint64(2), event.Count)
+	assert.Equal(t, "quux", (<-events).Name)
+	assert.Empty(t, events)
+}
+
+func TestBus_Publish_concurrent(t *testing.T) {
+	bus := NewBus(10)
+
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	const publishers, published = 4, 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		kind := strconv.Itoa(i)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < published; j++ {
+				bus.Publish(Event{Type: Added, Kind: kind, Name: strconv.Itoa(j)})
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	last := make(map[string]int)
+	var received, reported uint64
+
+	receive := func(event Event) {
+		if event.Type == Dropped {
+			reported += event.Count
+			return
+		}
+
+		received++
+
+		// The events of each publisher are received in order.
+		index, err := strconv.Atoi(event.Name)
+		require.NoError(t, err)
+		if previous, ok := last[event.Kind]; ok {
+			assert.Greater(t, index, previous)
+		}
+		last[event.Kind] = index
+	}
+
+	for {
+		select {
+		case event := <-events:
+			receive(event)
+			continue
+		case <-done:
+		}
+		break
+	}
+
+	for len(events) > 0 {
+		receive(<-events)
+	}
+
+	assert.Equal(t, uint64(publishers*published), received+bus.Dropped())
+	assert.LessOrEqual(t, reported, bus.Dropped())
+}
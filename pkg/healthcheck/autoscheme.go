@@ -0,0 +1,80 @@
+package healthcheck
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// scheme returns the scheme of the HTTP checks of the given server, empty for the scheme of the server URL.
+// With the AutoScheme, it is the scheme the server last answered on, HTTPS until it answered.
+func (b *BackendConfig) scheme(serverURL *url.URL) string {
+	if b.Scheme != AutoScheme {
+		return b.Scheme
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if scheme, ok := b.autoSchemes[serverURL.String()]; ok {
+		return scheme
+	}
+	return "https"
+}
+
+// recordScheme remembers the scheme the given server answered on, with the AutoScheme.
+func (b *BackendConfig) recordScheme(serverURL *url.URL, scheme string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.autoSchemes == nil {
+		b.autoSchemes = make(map[string]string)
+	}
+	b.autoSchemes[serverURL.String()] = scheme
+}
+
+// forgetScheme forgets the scheme the given server answered on, so that it is resolved again on the next check.
+func (b *BackendConfig) forgetScheme(serverURL *url.URL) {
+	if b.Scheme != AutoScheme {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.autoSchemes, serverURL.String())
+}
+
+// do sends the given HTTP check request of the server.
+// With the AutoScheme, an HTTPS request to a server not speaking TLS is sent again over HTTP,
+// and the scheme the server answered on is remembered.
+func (b *BackendConfig) do(client *http.Client, serverURL *url.URL, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if b.Scheme != AutoScheme {
+		return resp, err
+	}
+
+	scheme := req.URL.Scheme
+	if err != nil && scheme == "https" && notSpeakingTLS(err) {
+		httpReq := req.Clone(req.Context())
+		httpReq.URL.Scheme = "http"
+		scheme = httpReq.URL.Scheme
+
+		resp, err = client.Do(httpReq)
+	}
+
+	if err == nil {
+		b.recordScheme(serverURL, scheme)
+	}
+
+	return resp, err
+}
+
+// notSpeakingTLS returns whether the given error of an HTTPS request denotes a server not speaking TLS.
+// The other TLS errors, e.g. an untrusted certificate, do not make the checks fall back to HTTP.
+func notSpeakingTLS(err error) bool {
+	var recordHeaderErr tls.RecordHeaderError
+	return errors.As(err, &recordHeaderErr) || strings.Contains(err.Error(), "server gave HTTP response to HTTPS client")
+}
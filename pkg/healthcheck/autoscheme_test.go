@@ -0,0 +1,103 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckHealth_AutoScheme(t *testing.T) {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	httpsServer := httptest.NewTLSServer(handler)
+	t.Cleanup(httpsServer.Close)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(httpsServer.Certificate())
+
+	testCases := []struct {
+		desc           string
+		server         *httptest.Server
+		expectedScheme string
+	}{
+		{
+			desc:           "server only speaking HTTP",
+			server:         httpServer,
+			expectedScheme: "http",
+		},
+		{
+			desc:           "server only speaking HTTPS",
+			server:         httpsServer,
+			expectedScheme: "https",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend, err := NewBackendConfig(Options{
+				Scheme:    AutoScheme,
+				Path:      "/health",
+				Timeout:   time.Second,
+				TLSConfig: &tls.Config{RootCAs: rootCAs},
+			}, "backendName")
+			require.NoError(t, err)
+
+			serverURL := testhelpers.MustParseURL(test.server.URL)
+
+			// Until the server answered, HTTPS is tried first.
+			assert.Equal(t, "https", backend.scheme(serverURL))
+
+			require.NoError(t, checkHealth(context.Background(), serverURL, backend))
+			assert.Equal(t, test.expectedScheme, backend.scheme(serverURL))
+
+			// The scheme the server answered on is reused by the next checks.
+			req, err := backend.newRequest(serverURL)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedScheme, req.URL.Scheme)
+
+			require.NoError(t, checkHealth(context.Background(), serverURL, backend))
+			assert.Equal(t, test.expectedScheme, backend.scheme(serverURL))
+		})
+	}
+}
+
+func TestCheckHealth_AutoScheme_forgotten(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	backend, err := NewBackendConfig(Options{
+		Scheme:  AutoScheme,
+		Path:    "/health",
+		Timeout: time.Second,
+	}, "backendName")
+	require.NoError(t, err)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+
+	// The scheme is resolved again on the next check of a failing server.
+	assert.Error(t, checkHealth(context.Background(), serverURL, backend))
+	assert.Equal(t, "https", backend.scheme(serverURL))
+}
+
+func TestNewBackendConfig_AutoScheme(t *testing.T) {
+	_, err := NewBackendConfig(Options{Mode: GRPCMode, Scheme: AutoScheme}, "backendName")
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package healthcheck
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// basicAuthFileRefreshPeriod is the duration the credentials read from a basic auth file are reused before reading it again.
+var basicAuthFileRefreshPeriod = time.Minute
+
+// BasicAuth are HTTP basic authentication credentials.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// basicAuthFile caches the credentials read from a basic auth file.
+type basicAuthFile struct {
+	mu     sync.Mutex
+	readAt time.Time
+	auth   *BasicAuth
+}
+
+// credentials returns the credentials of the given file, reading it again once the refresh period elapsed.
+func (f *basicAuthFile) credentials(path string, now time.Time) (*BasicAuth, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.auth != nil && now.Sub(f.readAt) < basicAuthFileRefreshPeriod {
+		return f.auth, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password, ok := strings.Cut(strings.TrimSpace(string(content)), ":")
+	if !ok {
+		return nil, errors.New("the credentials must be formatted as user:password")
+	}
+
+	f.auth = &BasicAuth{Username: username, Password: password}
+	f.readAt = now
+
+	return f.auth, nil
+}
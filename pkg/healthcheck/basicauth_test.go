@@ -0,0 +1,122 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckHealth_basicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+
+	validFile := filepath.Join(dir, "valid")
+	require.NoError(t, os.WriteFile(validFile, []byte("user:secret\n"), 0o600))
+
+	invalidFile := filepath.Join(dir, "invalid")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("user"), 0o600))
+
+	testCases := []struct {
+		desc          string
+		basicAuth     *BasicAuth
+		basicAuthFile string
+		expectErr     bool
+	}{
+		{
+			desc:      "without credentials",
+			expectErr: true,
+		},
+		{
+			desc:      "inline credentials",
+			basicAuth: &BasicAuth{Username: "user", Password: "secret"},
+		},
+		{
+			desc:      "wrong inline credentials",
+			basicAuth: &BasicAuth{Username: "user", Password: "wrong"},
+			expectErr: true,
+		},
+		{
+			desc:          "credentials file",
+			basicAuthFile: validFile,
+		},
+		{
+			desc:          "credentials file superseding the inline credentials",
+			basicAuth:     &BasicAuth{Username: "user", Password: "wrong"},
+			basicAuthFile: validFile,
+		},
+		{
+			desc:          "missing credentials file",
+			basicAuthFile: filepath.Join(dir, "missing"),
+			expectErr:     true,
+		},
+		{
+			desc:          "invalid credentials file",
+			basicAuthFile: invalidFile,
+			expectErr:     true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend, err := NewBackendConfig(Options{
+				Path:          "/health",
+				Timeout:       time.Second,
+				BasicAuth:     test.basicAuth,
+				BasicAuthFile: test.basicAuthFile,
+			}, "backendName")
+			require.NoError(t, err)
+
+			err = checkHealth(context.Background(), testhelpers.MustParseURL(server.URL), backend)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBasicAuthFile_rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("user:old"), 0o600))
+
+	var file basicAuthFile
+
+	now := time.Now()
+	auth, err := file.credentials(path, now)
+	require.NoError(t, err)
+	assert.Equal(t, &BasicAuth{Username: "user", Password: "old"}, auth)
+
+	require.NoError(t, os.WriteFile(path, []byte("user:new"), 0o600))
+
+	// The file is not read again before the refresh period elapsed.
+	auth, err = file.credentials(path, now.Add(basicAuthFileRefreshPeriod/2))
+	require.NoError(t, err)
+	assert.Equal(t, &BasicAuth{Username: "user", Password: "old"}, auth)
+
+	auth, err = file.credentials(path, now.Add(basicAuthFileRefreshPeriod))
+	require.NoError(t, err)
+	assert.Equal(t, &BasicAuth{Username: "user", Password: "new"}, auth)
+}
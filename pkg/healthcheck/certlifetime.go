@@ -0,0 +1,72 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// checkCertLifetime returns an error when the certificate chain presented by the server in the given response of an HTTPS check
+// expires within the MinCertLifetime: errDegraded, or errCertExpiring with MinCertLifetimeDown.
+// It also records the remaining lifetime of the chain, i.e. the one of its certificate expiring first.
+func (b *BackendConfig) checkCertLifetime(serverURL *url.URL, resp *http.Response, now time.Time) error {
+	if b.MinCertLifetime <= 0 || resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+
+	notAfter := resp.TLS.PeerCertificates[0].NotAfter
+	for _, cert := range resp.TLS.PeerCertificates[1:] {
+		if cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+
+	lifetime := notAfter.Sub(now)
+	b.recordCertLifetime(serverURL, lifetime)
+
+	if lifetime >= b.MinCertLifetime {
+		return nil
+	}
+
+	if b.MinCertLifetimeDown {
+		return fmt.Errorf("%w: expires in %s", errCertExpiring, lifetime.Truncate(time.Second))
+	}
+
+	return fmt.Errorf("%w: the certificate expires in %s", errDegraded, lifetime.Truncate(time.Second))
+}
+
+// recordCertLifetime remembers the remaining lifetime of the certificate chain presented by the given server.
+func (b *BackendConfig) recordCertLifetime(serverURL *url.URL, lifetime time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.certLifetimes == nil {
+		b.certLifetimes = make(map[string]time.Duration)
+	}
+	b.certLifetimes[serverURL.String()] = lifetime
+}
+
+// certLifetime returns the remaining lifetime of the certificate chain presented by the given server on its last HTTPS check,
+// and whether there was one.
+func (b *BackendConfig) certLifetime(serverURL *url.URL) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lifetime, ok := b.certLifetimes[serverURL.String()]
+	return lifetime, ok
+}
+
+// setCertLifetime reports the remaining lifetime of the certificate chain presented by the given server, if any.
+func (hc *HealthCheck) setCertLifetime(backend *BackendConfig, serverURL *url.URL) {
+	if hc.metrics.certLifetimeGauge == nil {
+		return
+	}
+
+	lifetime, ok := backend.certLifetime(serverURL)
+	if !ok {
+		return
+	}
+
+	hc.metrics.certLifetimeGauge.With("service", backend.name, "url", serverURL.String()).Set(lifetime.Seconds())
+}
@@ -0,0 +1,135 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestCheckServersLB_MinCertLifetime(t *testing.T) {
+	cert, leaf := newShortLivedCertificate(t, time.Hour)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(leaf)
+
+	testCases := []struct {
+		desc                string
+		minCertLifetime     time.Duration
+		minCertLifetimeDown bool
+		expectedInLB        bool
+		expectedWeight      int
+	}{
+		{
+			desc:            "certificate outliving the minimum lifetime",
+			minCertLifetime: 30 * time.Minute,
+			expectedInLB:    true,
+			expectedWeight:  10,
+		},
+		{
+			desc:            "certificate expiring within the minimum lifetime",
+			minCertLifetime: 2 * time.Hour,
+			expectedInLB:    true,
+			expectedWeight:  2,
+		},
+		{
+			desc:                "certificate expiring within the minimum lifetime, down",
+			minCertLifetime:     2 * time.Hour,
+			minCertLifetimeDown: true,
+			expectedInLB:        false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			serverURL := testhelpers.MustParseURL(server.URL)
+
+			rr, err := roundrobin.New(http.NotFoundHandler())
+			require.NoError(t, err)
+			require.NoError(t, rr.UpsertServer(serverURL, roundrobin.Weight(10)))
+
+			backend, err := NewBackendConfig(Options{
+				Path:                "/health",
+				Interval:            healthCheckInterval,
+				Timeout:             healthCheckTimeout,
+				TLSConfig:           &tls.Config{RootCAs: rootCAs},
+				DegradedWeight:      2,
+				MinCertLifetime:     test.minCertLifetime,
+				MinCertLifetimeDown: test.minCertLifetimeDown,
+				LB:                  NewLBStatusUpdater(rr, &runtime.ServiceInfo{}, nil),
+			}, "backendName")
+			require.NoError(t, err)
+
+			collectingGauge := &testhelpers.CollectingGauge{}
+			check := HealthCheck{
+				Backends: make(map[string]*BackendConfig),
+				metrics: metricsHealthcheck{
+					serverUpGauge:     &testhelpers.CollectingGauge{},
+					certLifetimeGauge: collectingGauge,
+				},
+			}
+			check.checkServersLB(context.Background(), backend)
+
+			assert.InDelta(t, time.Hour.Seconds(), collectingGauge.GaugeValue, time.Minute.Seconds())
+			assert.Equal(t, []string{"service", "backendName", "url", serverURL.String()}, collectingGauge.LastLabelValues)
+
+			weight, ok := rr.ServerWeight(serverURL)
+			require.Equal(t, test.expectedInLB, ok)
+			if !test.expectedInLB {
+				return
+			}
+			assert.Equal(t, test.expectedWeight, weight)
+		})
+	}
+}
+
+// newShortLivedCertificate returns a self-signed certificate for 127.0.0.1, expiring after the given lifetime.
+func newShortLivedCertificate(t *testing.T, lifetime time.Duration) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(lifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, leaf
+}
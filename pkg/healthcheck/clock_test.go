@@ -0,0 +1,229 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/metrics"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestHealthCheck_SetClock(t *testing.T) {
+	checks := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		checks <- struct{}{}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{testhelpers.MustParseURL(server.URL)}}
+
+	backend, err := NewBackendConfig(Options{
+		Path:     "/path",
+		Interval: time.Hour,
+		Timeout:  healthCheckTimeout,
+		LB:       lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+
+	check := newHealthCheck(metrics.NewVoidRegistry())
+	check.SetClock(clock)
+	check.SetBackendsConfiguration(context.Background(), map[string]*BackendConfig{"backendName": backend})
+	t.Cleanup(func() { require.NoError(t, check.Stop(context.Background())) })
+
+	waitCheck := func() {
+		t.Helper()
+
+		select {
+		case <-checks:
+		case <-time.After(time.Second):
+			t.Fatal("the server was not checked")
+		}
+	}
+
+	// The initial check runs before the ticker is created.
+	waitCheck()
+	clock.waitTicker(t)
+
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Hour)
+		waitCheck()
+	}
+
+	require.Empty(t, checks)
+}
+
+func TestHealthCheck_SetClock_events(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{testhelpers.MustParseURL(server.URL)}}
+
+	events := make(chan StatusEvent, 1)
+	backend, err := NewBackendConfig(Options{
+		Path:      "/path",
+		Interval:  time.Hour,
+		Timeout:   healthCheckTimeout,
+		EventChan: events,
+		LB:        lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+	clock.Advance(-24 * time.Hour)
+
+	check := newHealthCheck(metrics.NewVoidRegistry())
+	check.SetClock(clock)
+	check.SetBackendsConfiguration(context.Background(), map[string]*BackendConfig{"backendName": backend})
+	t.Cleanup(func() { require.NoError(t, check.Stop(context.Background())) })
+
+	// The events are timed by the clock of the health check.
+	select {
+	case event := <-events:
+		assert.Equal(t, clock.Now(), event.Time)
+	case <-time.After(time.Second):
+		t.Fatal("no event was published")
+	}
+}
+
+// fakeClock is a Clock whose time only advances on Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	tickers []*fakeTicker
+	// created is signaled on each timer and ticker creation.
+	created chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Now(),
+		created: make(chan struct{}, 10),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{clock: c, c: make(chan time.Time, 1), deadline: c.now.Add(d)}
+	c.timers = append(c.timers, timer)
+	c.created <- struct{}{}
+
+	return timer
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ticker := &fakeTicker{clock: c, c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, ticker)
+	c.created <- struct{}{}
+
+	return ticker
+}
+
+// Advance moves the time forward, firing the timers and tickers due in the meantime.
+// Like the ones of the time package, the tickers drop the ticks a slow receiver misses.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	for _, timer := range c.timers {
+		if !timer.stopped && !timer.deadline.After(c.now) {
+			timer.stopped = true
+			timer.c <- timer.deadline
+		}
+	}
+
+	for _, ticker := range c.tickers {
+		for !ticker.stopped && !ticker.next.After(c.now) {
+			select {
+			case ticker.c <- ticker.next:
+			default:
+			}
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+}
+
+// waitTicker waits for the creation of a timer or a ticker.
+func (c *fakeClock) waitTicker(t *testing.T) {
+	t.Helper()
+
+	select {
+	case <-c.created:
+	case <-time.After(time.Second):
+		t.Fatal("no ticker was created")
+	}
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	c        chan time.Time
+	deadline time.Time
+	stopped  bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := !t.stopped
+	t.stopped = true
+	return active
+}
+
+type fakeTicker struct {
+	clock   *fakeClock
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.period = d
+	t.next = t.clock.now.Add(d)
+	t.stopped = false
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.stopped = true
+}
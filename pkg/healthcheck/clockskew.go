@@ -0,0 +1,36 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// checkClockSkew returns an error when the Date header of the given response of an HTTP check
+// differs from the given local time by more than the MaxClockSkew: errDegraded, or errClockSkewed with MaxClockSkewDown.
+// The responses without a valid Date header are not checked.
+func (b *BackendConfig) checkClockSkew(resp *http.Response, now time.Time) error {
+	if b.MaxClockSkew <= 0 {
+		return nil
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return nil
+	}
+
+	skew := date.Sub(now)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew <= b.MaxClockSkew {
+		return nil
+	}
+
+	if b.MaxClockSkewDown {
+		return fmt.Errorf("%w: the Date header %s is %s away from the local time", errClockSkewed, date.Format(http.TimeFormat), skew.Truncate(time.Second))
+	}
+
+	return fmt.Errorf("%w: the Date header %s is %s away from the local time", errDegraded, date.Format(http.TimeFormat), skew.Truncate(time.Second))
+}
@@ -0,0 +1,89 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckHealth_MaxClockSkew(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		skew         time.Duration
+		maxClockSkew time.Duration
+		down         bool
+		expectedErr  error
+	}{
+		{
+			desc:         "Date header within the max clock skew",
+			skew:         time.Minute,
+			maxClockSkew: 5 * time.Minute,
+		},
+		{
+			desc:         "far-future Date header",
+			skew:         24 * time.Hour,
+			maxClockSkew: 5 * time.Minute,
+			expectedErr:  errDegraded,
+		},
+		{
+			desc:         "far-past Date header",
+			skew:         -24 * time.Hour,
+			maxClockSkew: 5 * time.Minute,
+			expectedErr:  errDegraded,
+		},
+		{
+			desc:         "far-future Date header, down",
+			skew:         24 * time.Hour,
+			maxClockSkew: 5 * time.Minute,
+			down:         true,
+			expectedErr:  errClockSkewed,
+		},
+		{
+			desc: "far-future Date header, no clock skew check",
+			skew: 24 * time.Hour,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Date", time.Now().Add(test.skew).UTC().Format(http.TimeFormat))
+				rw.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			backend, err := NewBackendConfig(Options{
+				Path:             "/health",
+				Timeout:          time.Second,
+				MaxClockSkew:     test.maxClockSkew,
+				MaxClockSkewDown: test.down,
+			}, "backendName")
+			require.NoError(t, err)
+
+			err = checkHealth(context.Background(), testhelpers.MustParseURL(server.URL), backend)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				assert.Contains(t, err.Error(), "away from the local time")
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckClockSkew_invalidDate(t *testing.T) {
+	backend := &BackendConfig{Options: Options{MaxClockSkew: time.Minute}}
+
+	resp := &http.Response{Header: http.Header{"Date": []string{"yesterday"}}}
+	assert.NoError(t, backend.checkClockSkew(resp, time.Now()))
+}
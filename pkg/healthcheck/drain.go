@@ -0,0 +1,213 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+// Drainer should be implemented by a Balancer able to drain the in-flight requests of a server before reporting it as down.
+type Drainer interface {
+	DrainServer(u *url.URL, duration time.Duration) error
+}
+
+// InFlightCounter is a handler counting the in-flight requests of each server,
+// meant to wrap the handler forwarding the requests load-balanced to the servers.
+type InFlightCounter struct {
+	next http.Handler
+
+	mu     sync.Mutex
+	counts map[string]int
+	// idle holds the channels closed once the servers have no more in-flight requests.
+	idle map[string][]chan struct{}
+}
+
+// NewInFlightCounter returns a new InFlightCounter.
+func NewInFlightCounter(next http.Handler) *InFlightCounter {
+	return &InFlightCounter{
+		next:   next,
+		counts: make(map[string]int),
+		idle:   make(map[string][]chan struct{}),
+	}
+}
+
+func (c *InFlightCounter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The load-balancer sets the URL of the request to the one of the selected server.
+	key := serverKey(req.URL)
+
+	c.mu.Lock()
+	c.counts[key]++
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.counts[key]--
+		if c.counts[key] > 0 {
+			return
+		}
+
+		delete(c.counts, key)
+		for _, idle := range c.idle[key] {
+			close(idle)
+		}
+		delete(c.idle, key)
+	}()
+
+	c.next.ServeHTTP(rw, req)
+}
+
+// Count returns the number of in-flight requests of the given server.
+func (c *InFlightCounter) Count(u *url.URL) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts[serverKey(u)]
+}
+
+// waitIdle returns a channel closed once the given server has no more in-flight requests.
+func (c *InFlightCounter) waitIdle(u *url.URL) <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	idle := make(chan struct{})
+
+	key := serverKey(u)
+	if c.counts[key] == 0 {
+		close(idle)
+		return idle
+	}
+
+	c.idle[key] = append(c.idle[key], idle)
+	return idle
+}
+
+// SetInFlightCounter sets the counter of the in-flight requests of the servers,
+// which allows to drain them before reporting them as down.
+func (lb *LbStatusUpdater) SetInFlightCounter(counter *InFlightCounter) {
+	lb.inFlight = counter
+}
+
+// DrainServer removes the given server from the BalancerHandler, so it does not receive new requests,
+// and updates the status of the server to "DRAINING" until it has no more in-flight requests,
+// or the given duration elapsed, after which its status is updated to "DOWN".
+// Without an InFlightCounter, the server is removed right away.
+func (lb *LbStatusUpdater) DrainServer(u *url.URL, duration time.Duration) error {
+	if lb.inFlight == nil || duration <= 0 {
+		return lb.RemoveServer(u)
+	}
+
+	ctx := context.TODO()
+	upBefore := len(lb.BalancerHandler.Servers()) > 0
+	err := lb.BalancerHandler.RemoveServer(u)
+	if err != nil {
+		return err
+	}
+
+	done := lb.startDraining(u)
+	log.FromContext(ctx).Debugf("child %s now %s", u.String(), serverDraining)
+
+	safe.Go(func() {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+
+		select {
+		case <-lb.inFlight.waitIdle(u):
+		case <-timer.C:
+		case <-done:
+			// The server status changed in the meantime.
+			return
+		}
+
+		if lb.stopDraining(u, done) {
+			log.FromContext(ctx).Debugf("child %s now %s", u.String(), serverDown)
+		}
+	})
+
+	lb.propagateRemoval(ctx, upBefore)
+	return nil
+}
+
+// startDraining updates the status of the given server to "DRAINING",
+// and returns the channel closed once it stops draining.
+func (lb *LbStatusUpdater) startDraining(u *url.URL) chan struct{} {
+	lb.drainingMu.Lock()
+	defer lb.drainingMu.Unlock()
+
+	lb.cancelDraining(u)
+
+	done := make(chan struct{})
+	if lb.draining == nil {
+		lb.draining = make(map[string]chan struct{})
+	}
+	lb.draining[u.String()] = done
+
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerStatus(u.String(), serverDraining)
+	}
+
+	return done
+}
+
+// stopDraining updates the status of the given server to "DOWN",
+// unless it stopped draining in the meantime, and returns whether it did.
+func (lb *LbStatusUpdater) stopDraining(u *url.URL, done chan struct{}) bool {
+	lb.drainingMu.Lock()
+	defer lb.drainingMu.Unlock()
+
+	if lb.draining[u.String()] != done {
+		return false
+	}
+
+	lb.cancelDraining(u)
+
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerStatus(u.String(), serverDown)
+	}
+
+	return true
+}
+
+// setServerStatus updates the status of the given server, which stops its draining if any.
+func (lb *LbStatusUpdater) setServerStatus(u *url.URL, status string) {
+	lb.drainingMu.Lock()
+	defer lb.drainingMu.Unlock()
+
+	lb.cancelDraining(u)
+
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerStatus(u.String(), status)
+	}
+}
+
+// cancelDraining stops the draining of the given server, lb.drainingMu must be held.
+func (lb *LbStatusUpdater) cancelDraining(u *url.URL) {
+	done, ok := lb.draining[u.String()]
+	if !ok {
+		return
+	}
+
+	close(done)
+	delete(lb.draining, u.String())
+}
+
+// DrainServer drains the given server in all the Balancer able to, and removes it from the others.
+func (b Balancers) DrainServer(u *url.URL, duration time.Duration) error {
+	return b.apply(func(lb Balancer) error {
+		if mirror, ok := lb.(*MirrorBalancer); ok {
+			lb = mirror.Balancer
+		}
+
+		if drainer, ok := lb.(Drainer); ok {
+			return drainer.DrainServer(u, duration)
+		}
+
+		return lb.RemoveServer(u)
+	})
+}
@@ -0,0 +1,160 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// holdRequest sends a request to the given server through the given counter,
+// and returns once the request reached the handler of the counter.
+func holdRequest(t *testing.T, counter *InFlightCounter, serverURL *url.URL) {
+	t.Helper()
+
+	started := make(chan struct{})
+	go func() {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com", nil)
+		req.URL = serverURL
+		counter.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), startedKey{}, started)))
+	}()
+
+	<-started
+}
+
+type startedKey struct{}
+
+// newHoldingCounter returns a counter whose requests stay in flight until release is closed.
+func newHoldingCounter(release <-chan struct{}) *InFlightCounter {
+	return NewInFlightCounter(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(req.Context().Value(startedKey{}).(chan struct{}))
+		<-release
+	}))
+}
+
+func TestDrainServer(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		drainDuration  time.Duration
+		release        bool
+		expectDraining bool
+	}{
+		{
+			desc:          "immediate removal",
+			drainDuration: 0,
+		},
+		{
+			desc:           "removal once the in-flight requests are over",
+			drainDuration:  time.Hour,
+			release:        true,
+			expectDraining: true,
+		},
+		{
+			desc:           "removal once the drain duration elapsed",
+			drainDuration:  50 * time.Millisecond,
+			expectDraining: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := newHTTPServer(http.StatusServiceUnavailable)
+			serverURL, _ := server.Start(t, func() {})
+
+			lb := &testLoadBalancer{
+				RWMutex: &sync.RWMutex{},
+				servers: []*url.URL{serverURL},
+			}
+			svInfo := &runtime.ServiceInfo{}
+
+			release := make(chan struct{})
+			t.Cleanup(func() {
+				if !test.release {
+					close(release)
+				}
+			})
+
+			counter := newHoldingCounter(release)
+			lbsu := NewLBStatusUpdater(lb, svInfo, nil)
+			lbsu.SetInFlightCounter(counter)
+
+			backend, err := NewBackendConfig(Options{
+				Path:          "/path",
+				Interval:      healthCheckInterval,
+				Timeout:       healthCheckTimeout,
+				DrainDuration: test.drainDuration,
+				LB:            lbsu,
+			}, "backendName")
+			require.NoError(t, err)
+
+			holdRequest(t, counter, serverURL)
+			assert.Equal(t, 1, counter.Count(serverURL))
+
+			check := HealthCheck{
+				Backends: make(map[string]*BackendConfig),
+				metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+			}
+			check.checkServersLB(context.Background(), backend)
+
+			// The server no longer receives new requests.
+			assert.Equal(t, 1, lb.numRemovedServers)
+			assert.Empty(t, lbsu.Servers())
+
+			if test.expectDraining {
+				assert.Equal(t, serverDraining, svInfo.GetAllStatus()[serverURL.String()])
+			}
+
+			if test.release {
+				close(release)
+			}
+
+			assert.Eventually(t, func() bool {
+				return svInfo.GetAllStatus()[serverURL.String()] == serverDown
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestDrainServer_upsertedWhileDraining(t *testing.T) {
+	serverURL := testhelpers.MustParseURL("http://127.0.0.1:8080")
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		servers: []*url.URL{serverURL},
+	}
+	svInfo := &runtime.ServiceInfo{}
+
+	release := make(chan struct{})
+	counter := newHoldingCounter(release)
+	lbsu := NewLBStatusUpdater(lb, svInfo, nil)
+	lbsu.SetInFlightCounter(counter)
+
+	holdRequest(t, counter, serverURL)
+
+	require.NoError(t, lbsu.DrainServer(serverURL, time.Hour))
+	assert.Equal(t, serverDraining, svInfo.GetAllStatus()[serverURL.String()])
+
+	require.NoError(t, lbsu.UpsertServer(serverURL, roundrobin.Weight(1)))
+	assert.Equal(t, serverUp, svInfo.GetAllStatus()[serverURL.String()])
+
+	// The end of the in-flight requests does not report the server as down.
+	close(release)
+	assert.Eventually(t, func() bool {
+		return counter.Count(serverURL) == 0
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, serverUp, svInfo.GetAllStatus()[serverURL.String()])
+}
@@ -0,0 +1,110 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failureReason is the category of a health check failure, easing the triage of the failing servers.
+type failureReason string
+
+const (
+	failureDNS     failureReason = "dns"
+	failureRefused failureReason = "connection refused"
+	failureTLS     failureReason = "tls"
+	failureTimeout failureReason = "timeout"
+	failureStatus  failureReason = "status"
+	failureConfig  failureReason = "configuration"
+	failureOther   failureReason = "other"
+)
+
+// errNotServing is returned by the gRPC health checks of the servers not reporting the SERVING status.
+var errNotServing = errors.New("received gRPC status code")
+
+// errServiceUnknown is returned by the gRPC health checks of the servers not knowing the checked service,
+// which is distinct from a server not serving it.
+var errServiceUnknown = errors.New("gRPC server does not know the service")
+
+// errConfiguration is returned by the health checks failing because of their configuration, rather than the server.
+var errConfiguration = errors.New("health check configuration failure")
+
+// errCertExpiring is returned by the HTTPS health checks of the servers whose certificate expires within the MinCertLifetime,
+// with MinCertLifetimeDown.
+var errCertExpiring = errors.New("server certificate expiring")
+
+// errClockSkewed is returned by the HTTP health checks of the servers whose Date header is skewed beyond the MaxClockSkew,
+// with MaxClockSkewDown.
+var errClockSkewed = errors.New("server clock skewed")
+
+// errUnexpectedALPN is returned by the HTTPS health checks not negotiating the ExpectedALPN with the server.
+var errUnexpectedALPN = errors.New("unexpected application protocol")
+
+// classifyFailure returns the category of the given health check failure.
+func classifyFailure(err error) failureReason {
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) || errors.Is(err, errNotServing) || errors.Is(err, errServiceUnknown) {
+		return failureStatus
+	}
+
+	if errors.Is(err, errConfiguration) {
+		return failureConfig
+	}
+
+	if errors.Is(err, errCertExpiring) || errors.Is(err, errUnexpectedALPN) {
+		return failureTLS
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return failureTimeout
+		}
+		return failureDNS
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return failureTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return failureTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return failureRefused
+	}
+
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var certificateInvalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var recordHeaderErr tls.RecordHeaderError
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &certificateInvalidErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &recordHeaderErr) {
+		return failureTLS
+	}
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) && grpcErr.GRPCStatus().Code() == codes.DeadlineExceeded {
+		return failureTimeout
+	}
+
+	// The gRPC errors, and the TLS alerts sent by the servers, only describe their cause in their message.
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "connection refused"):
+		return failureRefused
+	case strings.Contains(msg, "tls: "), strings.Contains(msg, "x509: "):
+		return failureTLS
+	}
+
+	return failureOther
+}
@@ -0,0 +1,109 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}))
+	t.Cleanup(slowServer.Close)
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(tlsServer.Close)
+
+	unavailableServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unavailableServer.Close)
+
+	// Nothing listens on the port of a closed listener.
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedURL := testhelpers.MustParseURL("http://" + listener.Addr().String())
+	require.NoError(t, listener.Close())
+
+	notServingURL, _ := newGRPCServer(healthpb.HealthCheckResponse_NOT_SERVING).Start(t, func() {})
+
+	dnsServer := &DNSServer{}
+	resolver := dnsServer.Resolver(t)
+
+	testCases := []struct {
+		desc           string
+		serverURL      *url.URL
+		options        Options
+		expectedReason failureReason
+	}{
+		{
+			desc:           "timeout",
+			serverURL:      testhelpers.MustParseURL(slowServer.URL),
+			options:        Options{Path: "/health", Timeout: 100 * time.Millisecond},
+			expectedReason: failureTimeout,
+		},
+		{
+			desc:           "connection refused",
+			serverURL:      closedURL,
+			options:        Options{Path: "/health", Timeout: time.Second},
+			expectedReason: failureRefused,
+		},
+		{
+			desc:           "TLS",
+			serverURL:      testhelpers.MustParseURL(tlsServer.URL),
+			options:        Options{Path: "/health", Timeout: time.Second},
+			expectedReason: failureTLS,
+		},
+		{
+			desc:           "status",
+			serverURL:      testhelpers.MustParseURL(unavailableServer.URL),
+			options:        Options{Path: "/health", Timeout: time.Second},
+			expectedReason: failureStatus,
+		},
+		{
+			desc:           "DNS",
+			serverURL:      testhelpers.MustParseURL("http://backend.example.com"),
+			options:        Options{Mode: DNSMode, Timeout: time.Second, Resolver: resolver},
+			expectedReason: failureDNS,
+		},
+		{
+			desc:           "gRPC connection refused",
+			serverURL:      closedURL,
+			options:        Options{Mode: GRPCMode, Timeout: time.Second},
+			expectedReason: failureRefused,
+		},
+		{
+			desc:           "gRPC status",
+			serverURL:      notServingURL,
+			options:        Options{Mode: GRPCMode, Timeout: time.Second},
+			expectedReason: failureStatus,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend, err := NewBackendConfig(test.options, "backendName")
+			require.NoError(t, err)
+
+			err = checkHealth(context.Background(), test.serverURL, backend)
+			require.Error(t, err)
+
+			assert.Equal(t, test.expectedReason, classifyFailure(err))
+		})
+	}
+}
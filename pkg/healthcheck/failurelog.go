@@ -0,0 +1,49 @@
+package healthcheck
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// failureLog holds the last failure logged for a server, and the number of identical failures not logged since.
+type failureLog struct {
+	reason     string
+	suppressed int
+}
+
+// logFailure records the given failure of a check of the given server, and returns whether it is to be logged,
+// the failures identical to the last one logged being suppressed until the server recovers.
+func (b *BackendConfig) logFailure(u *url.URL, err error) bool {
+	if b.failureLogs == nil {
+		b.failureLogs = make(map[string]*failureLog)
+	}
+
+	state, ok := b.failureLogs[u.String()]
+	if !ok {
+		state = &failureLog{}
+		b.failureLogs[u.String()] = state
+	}
+
+	if ok && state.reason == err.Error() {
+		state.suppressed++
+		return false
+	}
+
+	state.reason = err.Error()
+	return true
+}
+
+// logRecovery logs the recovery of the given server, along with the number of failures suppressed since its first failure,
+// if a failure of the server was logged.
+func (b *BackendConfig) logRecovery(ctx context.Context, u *url.URL) {
+	state, ok := b.failureLogs[u.String()]
+	if !ok {
+		return
+	}
+
+	delete(b.failureLogs, u.String())
+
+	log.FromContext(ctx).Warnf("Health check recovered. Backend: %q URL: %q Suppressed failures: %d", b.name, u.String(), state.suppressed)
+}
@@ -0,0 +1,80 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckServersLB_failureLogs(t *testing.T) {
+	hook := logtest.NewLocal(logrus.StandardLogger())
+
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if healthy.Load() {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{testhelpers.MustParseURL(server.URL)}}
+
+	backend, err := NewBackendConfig(Options{
+		Path:     "/path",
+		Interval: healthCheckInterval,
+		Timeout:  healthCheckTimeout,
+		LB:       lb,
+	}, "failingBackend")
+	require.NoError(t, err)
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+
+	checkServers := func(up bool) {
+		t.Helper()
+
+		healthy.Store(up)
+		check.probes.reset()
+		check.checkServersLB(context.Background(), backend)
+	}
+
+	// The server is removed on its first failure, and keeps failing for the same reason.
+	for i := 0; i < 4; i++ {
+		checkServers(false)
+	}
+	checkServers(true)
+
+	var failures, recoveries []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Level != logrus.WarnLevel || !strings.Contains(entry.Message, `"failingBackend"`) {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(entry.Message, "Health check failed"), strings.HasPrefix(entry.Message, "Health check still failing"):
+			failures = append(failures, entry.Message)
+		case strings.HasPrefix(entry.Message, "Health check recovered"):
+			recoveries = append(recoveries, entry.Message)
+		}
+	}
+
+	assert.Len(t, failures, 1)
+	require.Len(t, recoveries, 1)
+	assert.True(t, strings.HasSuffix(recoveries[0], "Suppressed failures: 3"), recoveries[0])
+	assert.Len(t, lb.Servers(), 1)
+}
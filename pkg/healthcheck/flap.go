@@ -0,0 +1,94 @@
+package healthcheck
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// defaultFlapWindowIntervals is the default FlapWindow, as a number of check intervals.
+const defaultFlapWindowIntervals = 10
+
+// flapState holds the recent state changes of a server.
+type flapState struct {
+	transitions []time.Time
+	warnedAt    time.Time
+	heldUntil   time.Time
+}
+
+// flapWindow returns the rolling window over which the state changes of a server are counted.
+func (b *BackendConfig) flapWindow() time.Duration {
+	if b.FlapWindow > 0 {
+		return b.FlapWindow
+	}
+	return defaultFlapWindowIntervals * b.Interval
+}
+
+// recordTransition records a state change of the given server at the given time, to the down state or back up,
+// and returns whether the server is flapping and was not reported as such within the FlapWindow yet.
+// A server flapping to the down state is held down for the FlapCooldown.
+func (b *BackendConfig) recordTransition(u *url.URL, down bool, now time.Time) bool {
+	if b.FlapThreshold <= 0 {
+		return false
+	}
+
+	if b.flaps == nil {
+		b.flaps = make(map[string]*flapState)
+	}
+
+	state, ok := b.flaps[u.String()]
+	if !ok {
+		state = &flapState{}
+		b.flaps[u.String()] = state
+	}
+
+	// The state changes which left the window are evicted.
+	window := b.flapWindow()
+	recent := state.transitions[:0]
+	for _, transition := range state.transitions {
+		if now.Sub(transition) < window {
+			recent = append(recent, transition)
+		}
+	}
+	state.transitions = append(recent, now)
+
+	if len(state.transitions) < b.FlapThreshold {
+		return false
+	}
+
+	if down && b.FlapCooldown > 0 {
+		state.heldUntil = now.Add(b.FlapCooldown)
+	}
+
+	if !state.warnedAt.IsZero() && now.Sub(state.warnedAt) < window {
+		return false
+	}
+
+	state.warnedAt = now
+	return true
+}
+
+// heldDown returns whether the given server, which flapped, is held down at the given time.
+func (b *BackendConfig) heldDown(u *url.URL, now time.Time) bool {
+	state, ok := b.flaps[u.String()]
+	return ok && now.Before(state.heldUntil)
+}
+
+// detectFlap records a state change of the given server at the given time, to the down state or back up,
+// and warns when the server is flapping.
+func (b *BackendConfig) detectFlap(ctx context.Context, u *url.URL, down bool, now time.Time) {
+	if !b.recordTransition(u, down, now) {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	if b.FlapCooldown > 0 {
+		logger.Warnf("Server flapping: at least %d state changes within %s, holding it down for %s after it goes down. Backend: %q URL: %q",
+			b.FlapThreshold, b.flapWindow(), b.FlapCooldown, b.name, u.String())
+		return
+	}
+
+	logger.Warnf("Server flapping: at least %d state changes within %s. Backend: %q URL: %q", b.FlapThreshold, b.flapWindow(), b.name, u.String())
+}
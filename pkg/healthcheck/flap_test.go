@@ -0,0 +1,118 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckServersLB_flapping(t *testing.T) {
+	hook := logtest.NewLocal(logrus.StandardLogger())
+
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if healthy.Load() {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	lb.servers = append(lb.servers, serverURL)
+
+	const cooldown = time.Hour
+
+	backend, err := NewBackendConfig(Options{
+		Path:          "/path",
+		Interval:      healthCheckInterval,
+		Timeout:       healthCheckTimeout,
+		FlapThreshold: 3,
+		FlapWindow:    time.Minute,
+		FlapCooldown:  cooldown,
+		LB:            lb,
+	}, "flappingBackend")
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+	check.SetClock(clock)
+
+	checkServers := func(up bool) {
+		t.Helper()
+
+		healthy.Store(up)
+		check.probes.reset()
+		check.checkServersLB(context.Background(), backend)
+	}
+
+	// Down, up, and down again: the third state change makes the server flapping, and holds it down.
+	checkServers(false)
+	checkServers(true)
+	checkServers(false)
+	assert.Equal(t, 1, flapWarnings(hook))
+
+	// The server is held down during the cooldown, even though it is healthy.
+	checkServers(true)
+	assert.Empty(t, lb.Servers())
+
+	// The cooldown follows the clock pacing the checks.
+	clock.Advance(cooldown)
+
+	checkServers(true)
+	assert.Len(t, lb.Servers(), 1)
+
+	// The server keeps flapping, but the warning is not repeated within the window.
+	checkServers(false)
+	assert.Empty(t, lb.Servers())
+	assert.Equal(t, 1, flapWarnings(hook))
+
+	assert.Equal(t, 3, lb.numRemovedServers)
+	assert.Equal(t, 2, lb.numUpsertedServers)
+}
+
+func TestRecordTransition_window(t *testing.T) {
+	backend := &BackendConfig{Options: Options{FlapThreshold: 2, FlapWindow: time.Minute}}
+	u := testhelpers.MustParseURL("http://127.0.0.1:8080")
+
+	now := time.Now()
+	assert.False(t, backend.recordTransition(u, true, now))
+	assert.True(t, backend.recordTransition(u, false, now.Add(time.Second)))
+
+	// The first state changes left the window.
+	assert.False(t, backend.recordTransition(u, true, now.Add(2*time.Minute)))
+	assert.True(t, backend.recordTransition(u, false, now.Add(2*time.Minute+time.Second)))
+
+	// No cooldown is configured.
+	assert.False(t, backend.heldDown(u, now.Add(2*time.Minute+time.Second)))
+}
+
+// flapWarnings returns the number of flap warnings of the flappingBackend logged so far.
+func flapWarnings(hook *logtest.Hook) int {
+	var count int
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel && strings.HasPrefix(entry.Message, "Server flapping") &&
+			strings.Contains(entry.Message, `"flappingBackend"`) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,171 @@
+package healthcheck
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcWatchMinBackoff is the delay before the first attempt to reopen a dropped gRPC Watch stream,
+// doubled on each failed attempt, up to the Interval.
+const grpcWatchMinBackoff = 100 * time.Millisecond
+
+// grpcWatcher holds the last statuses pushed by the gRPC Watch streams of the servers of a backend.
+type grpcWatcher struct {
+	mu sync.Mutex
+	// results holds, by server URL, the outcome of the last status pushed by the server,
+	// only for the servers with an open stream.
+	results map[string]error
+	// changes is signaled when a server pushes a status.
+	changes chan struct{}
+}
+
+func newGRPCWatcher() *grpcWatcher {
+	return &grpcWatcher{
+		results: make(map[string]error),
+		changes: make(chan struct{}, 1),
+	}
+}
+
+// result returns whether the given server has an open stream, and the outcome of the last status it pushed.
+// The servers without an open stream are checked with the unary Check.
+func (w *grpcWatcher) result(u *url.URL) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err, ok := w.results[u.String()]
+	return ok, err
+}
+
+func (w *grpcWatcher) set(u *url.URL, err error) {
+	w.mu.Lock()
+	w.results[u.String()] = err
+	w.mu.Unlock()
+
+	select {
+	case w.changes <- struct{}{}:
+	default:
+		// A round of checks is already pending.
+	}
+}
+
+func (w *grpcWatcher) unset(u *url.URL) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.results, u.String())
+}
+
+// watchServers opens a gRPC Watch stream to each server of the backend, until the given context is done.
+func (hc *HealthCheck) watchServers(ctx context.Context, backend *BackendConfig) {
+	backend.mu.Lock()
+	servers := backend.LB.Servers()
+	if !backend.ShadowMode {
+		// The disabled servers are not in the load-balancer.
+		for _, disabledURL := range backend.disabledURLs {
+			servers = append(servers, disabledURL.url)
+		}
+	}
+	backend.mu.Unlock()
+
+	for _, server := range servers {
+		serverURL := server
+		hc.running.Add(1)
+		safe.Go(func() {
+			defer hc.running.Done()
+			watchGRPC(ctx, serverURL, backend)
+		})
+	}
+}
+
+// watchGRPC keeps a gRPC Watch stream open to the given server, reopening it with a backoff when it drops,
+// until the given context is done. It returns for the servers not implementing Watch, which are polled instead.
+func watchGRPC(ctx context.Context, serverURL *url.URL, backend *BackendConfig) {
+	logger := log.FromContext(ctx)
+
+	maxBackoff := backend.Interval
+	if maxBackoff < grpcWatchMinBackoff {
+		maxBackoff = grpcWatchMinBackoff
+	}
+
+	backoff := grpcWatchMinBackoff
+	for {
+		received, err := watchStream(ctx, serverURL, backend)
+		backend.grpcWatcher.unset(serverURL)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if status.Code(err) == codes.Unimplemented {
+			logger.Debugf("gRPC server %s does not implement Watch, falling back to polling. Backend: %q", serverURL.String(), backend.name)
+			return
+		}
+
+		if received {
+			backoff = grpcWatchMinBackoff
+		}
+
+		logger.Debugf("gRPC Watch stream of the server %s dropped, reopening it in %s. Backend: %q Reason: %v", serverURL.String(), backoff, backend.name, err)
+
+		timer := backend.getClock().NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// watchStream records the statuses pushed by the given server through a gRPC Watch stream, until the stream drops,
+// and returns whether a status was received.
+func watchStream(ctx context.Context, serverURL *url.URL, backend *BackendConfig) (bool, error) {
+	serverAddr, err := grpcServerAddr(ctx, serverURL, backend)
+	if err != nil {
+		return false, err
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, backend.Options.Timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(dialCtx, serverAddr, grpcDialOptions(backend)...)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if len(backend.Options.GRPCMetadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(backend.Options.GRPCMetadata))
+	}
+
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{Service: backend.Options.GRPCServiceName})
+	if err != nil {
+		return false, err
+	}
+
+	var received bool
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return received, err
+		}
+
+		received = true
+		backend.grpcWatcher.set(serverURL, grpcServingStatus(ctx, serverAddr, backend, resp.Status))
+	}
+}
@@ -1,15 +1,23 @@
 package healthcheck
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	gokitmetrics "github.com/go-kit/kit/metrics"
@@ -18,24 +26,63 @@ import (
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/metrics"
 	"github.com/traefik/traefik/v2/pkg/safe"
+	"github.com/traefik/traefik/v2/pkg/types"
+	"github.com/traefik/traefik/v2/pkg/version"
 	"github.com/vulcand/oxy/roundrobin"
+	"golang.org/x/net/http2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 const (
-	serverUp   = "UP"
-	serverDown = "DOWN"
+	serverUp       = "UP"
+	serverDown     = "DOWN"
+	serverDraining = "DRAINING"
 )
 
+// errDegraded is returned by the HTTP health checks of the servers reporting a degraded state.
+var errDegraded = errors.New("server degraded")
+
+// errProbeSkipped is returned for the probes skipped for lack of a free probe slot.
+var errProbeSkipped = errors.New("probe skipped")
+
+// defaultMaxRedirects is the maximum number of redirects followed by default, the one of the Go HTTP client.
+const defaultMaxRedirects = 10
+
+// maxBodySize is the maximum number of bytes of a response body read to match the expected body.
+const maxBodySize = 64 * 1024
+
+// StopTimeout is the maximum duration to wait for the running checks to return when they are stopped,
+// i.e. when the backends are replaced, or on shutdown.
+const StopTimeout = 5 * time.Second
+
+// unixSocketHost is the placeholder host of the requests of the HTTP checks sent through a Unix domain socket.
+const unixSocketHost = "localhost"
+
 const (
 	HTTPMode = "http"
 	GRPCMode = "grpc"
+	TCPMode  = "tcp"
+	UDPMode  = "udp"
+	DNSMode  = "dns"
+	WSMode   = "ws"
+)
+
+// Interpretations of the gRPC checks reporting an unknown service.
+const (
+	GRPCUnknownDown = "down"
+	GRPCUnknownUp   = "up"
 )
 
+// AutoScheme is the Scheme making the HTTP checks try HTTPS first, and fall back to HTTP for the servers not speaking TLS,
+// the scheme each server answered on being reused by its next checks.
+const AutoScheme = "auto"
+
 var (
 	singleton *HealthCheck
 	once      sync.Once
@@ -62,27 +109,326 @@ type BalancerStatusHandler interface {
 }
 
 type metricsHealthcheck struct {
-	serverUpGauge gokitmetrics.Gauge
+	serverUpGauge        gokitmetrics.Gauge
+	serverFailuresGauge  gokitmetrics.Gauge
+	healthyRatioGauge    gokitmetrics.Gauge
+	disabledServersGauge gokitmetrics.Gauge
+	certLifetimeGauge    gokitmetrics.Gauge
+	checkRequests        gokitmetrics.Counter
+	checkDuration        gokitmetrics.Histogram
+	checkCycleDuration   gokitmetrics.Histogram
 }
 
 // Options are the public health check options.
 type Options struct {
 	Headers         map[string]string
-	Hostname        string
+	Hostname        string // May include a port, e.g. for the servers routing on a Host header like name:443.
 	Scheme          string
 	Mode            string
-	Path            string
+	Path            string // May hold the {host}, {port}, and {scheme} tokens, substituted with the ones of the server URL.
 	Method          string
 	Port            int
 	FollowRedirects bool
 	Transport       http.RoundTripper
 	Interval        time.Duration
 	Timeout         time.Duration
-	LB              Balancer
+	// DialTimeout bounds the connection phase (TCP and TLS handshakes) of the HTTP checks, within their overall Timeout.
+	DialTimeout time.Duration
+	// BackoffMaxInterval enables the exponential backoff of the checks of the failing servers:
+	// the interval between two checks of a server doubles on each consecutive failure, up to BackoffMaxInterval,
+	// and is reset to the Interval on the first success.
+	// The check ending a backoff wait is a half-open probe: a down server it finds healthy returns to the load-balancer at once,
+	// whatever the RiseThreshold, while a failure continues the backoff.
+	BackoffMaxInterval time.Duration
+	// InitialDelay is the duration to wait before the first check, during which the servers keep their initial state.
+	InitialDelay time.Duration
+	// IntervalJitter is the maximum random duration added to each interval, to spread the checks of the backends over time.
+	IntervalJitter time.Duration
+	// FailThreshold is the number of consecutive failed checks before a server is removed from the load-balancer.
+	FailThreshold int
+	// RiseThreshold is the number of consecutive successful checks before a server is returned to the load-balancer.
+	RiseThreshold int
+	// ExpectedStatus is the comma-separated list of status codes and status code ranges (e.g. 200-204,418)
+	// considered healthy for HTTP checks. When empty, the 2XX and 3XX status codes are considered healthy.
+	ExpectedStatus string
+	// TLSConfig is the TLS configuration used to check the servers over TLS, e.g. to trust a private CA or to present a client certificate.
+	// When set, it supersedes the TLS configuration of the Transport for HTTP checks.
+	TLSConfig *tls.Config
+	// ServerName is the server name presented with SNI, and verified against the server certificate, by the checks over TLS.
+	// Unlike the Hostname, it does not change the Host header of the HTTP check requests.
+	ServerName string
+	// ShadowMode makes the health check only report what it would do (logs and metrics),
+	// without ever removing or returning servers to the load-balancer.
+	ShadowMode bool
+	// GRPCServiceName is the name of the gRPC service to check, the overall health of the server is checked when empty.
+	GRPCServiceName string
+	// Body is the body of the HTTP check requests, only sent with the POST, PUT, and PATCH methods.
+	Body string
+	// ExpectedBody is a substring the body of the HTTP check responses must contain for the server to be healthy.
+	ExpectedBody string
+	// ExpectedBodyRegex is a regular expression the body of the HTTP check responses must match for the server to be healthy.
+	ExpectedBodyRegex string
+	// HTTPClient is the client used for the HTTP checks, e.g. to tune its connection pooling.
+	// When set, it supersedes the Transport, the TLSConfig, and the UnixSocket for HTTP checks, and its requests are bounded by the Timeout.
+	HTTPClient *http.Client
+	// PassiveWindow is the number of the last request outcomes of a server, reported with ReportResult,
+	// over which its error ratio is computed. The passive health check is disabled when zero.
+	PassiveWindow int
+	// PassiveErrorRatio is the error ratio above which the passive health check removes a server from the load-balancer.
+	PassiveErrorRatio float64
+	// UnixSocket is the path of the Unix domain socket the HTTP checks are sent through, instead of the address of the server.
+	UnixSocket string
+	// AuthToken is the bearer token sent in the Authorization header of the HTTP check requests.
+	AuthToken string
+	// AuthTokenFunc returns the bearer token sent in the Authorization header of each HTTP check request,
+	// e.g. to refresh it before it expires. When set, it supersedes the AuthToken, and its errors fail the checks.
+	AuthTokenFunc func() (string, error)
+	// DrainDuration is the maximum duration a server failing its checks keeps draining its in-flight requests,
+	// before being reported as down. It only applies to the load-balancers implementing Drainer.
+	DrainDuration time.Duration
+	// Paths are the candidate paths of the HTTP checks, tried in order until one of them is healthy.
+	// When set, they supersede the Path for HTTP checks. Like the Path, they may hold tokens.
+	Paths []string
+	// EventChan receives a StatusEvent on every status change of a server.
+	// The events are dropped when the channel is not ready to receive them, so a slow consumer does not stall the checks.
+	EventChan chan<- StatusEvent
+	// DegradedHeader and DegradedHeaderValue identify the responses of the HTTP checks reporting a degraded server,
+	// which stays in the load-balancer with the DegradedWeight until it is fully healthy again.
+	// An empty DegradedHeaderValue matches any value of the header.
+	DegradedHeader      string
+	DegradedHeaderValue string
+	// DegradedStatus is the status code of the responses of the HTTP checks reporting a degraded server.
+	DegradedStatus int
+	// DegradedWeight is the weight of the degraded servers in the load-balancer, defaults to 1.
+	DegradedWeight int
+	// HTTP2 makes the HTTP checks use HTTP/2: negotiated over TLS for the https scheme, and h2c (HTTP/2 without TLS) otherwise.
+	HTTP2 bool
+	// GRPCMetadata is the metadata sent with the gRPC check requests, the gRPC counterpart of the Headers.
+	GRPCMetadata map[string]string
+	// GRPCTreatUnknownAs is how the gRPC checks interpret the servers not knowing the checked service,
+	// or reporting an UNKNOWN or SERVICE_UNKNOWN status, which often denotes a misconfiguration rather than a down server:
+	// either GRPCUnknownDown, the default, or GRPCUnknownUp.
+	GRPCTreatUnknownAs string
+	// GRPCUseWatch makes the gRPC checks follow the statuses pushed by the servers through a Watch stream,
+	// instead of polling them with the unary Check on each Interval.
+	// The servers not implementing Watch are polled, as well as the servers whose stream dropped until it is reopened.
+	GRPCUseWatch bool
+	// SignRequest is called on each HTTP check request once all its other options applied, e.g. to attach a signature.
+	// Its errors fail the checks as configuration failures.
+	SignRequest func(*http.Request) error
+	// MaxRedirects is the maximum number of redirects followed by the HTTP checks following redirects,
+	// beyond which the check fails. Defaults to 10, like the Go HTTP client.
+	MaxRedirects int
+	// SameHostRedirectsOnly makes the HTTP checks following redirects fail on a redirect to another host.
+	SameHostRedirectsOnly bool
+	// UserAgent is the User-Agent header of the HTTP check requests, Traefik-HealthCheck/<version> when empty.
+	UserAgent string
+	// ServerOptions holds, by server URL, the options overriding the ones of the backend for a given server.
+	ServerOptions map[string]ServerOptions
+	// Resolver is the resolver of the DNS checks, and of the HTTP checks with ReResolve, net.DefaultResolver when nil.
+	Resolver *net.Resolver
+	// BasicAuth are the basic authentication credentials of the HTTP check requests.
+	BasicAuth *BasicAuth
+	// BasicAuthFile is the path of a file holding the basic authentication credentials, as user:password,
+	// re-read periodically to pick up the rotated credentials. It supersedes the BasicAuth.
+	BasicAuthFile string
+	// Evaluate decides whether the response of an HTTP check, received after the given latency, is healthy.
+	// When set, it replaces the built-in evaluation of the status code, body, and degraded state of the response.
+	Evaluate func(resp *http.Response, latency time.Duration) (bool, error)
+	// ProbeCacheTTL is the maximum age of the result of a probe reused by the backends checking the same target.
+	// Defaults to the Interval.
+	ProbeCacheTTL time.Duration
+	// PortFromHeader is the name of the header of the HTTP check responses through which the servers advertise the port to check,
+	// e.g. a dedicated management port. The advertised port takes precedence over the Port and the port of the server URL.
+	PortFromHeader string
+	// StartUnhealthy makes the servers of the load-balancer start as down,
+	// so that they only receive traffic once a check succeeded.
+	StartUnhealthy bool
+	// KeepLastHealthy makes the last server of the load-balancer stay in it even when it fails its checks,
+	// so that the load-balancer is never left without servers (fail open).
+	KeepLastHealthy bool
+	// StartupGracePeriod is the duration, from the first round of checks of the backend configuration (e.g. on a configuration reload),
+	// during which the servers failing their checks are not removed from the load-balancer, while the servers down can still return to it.
+	StartupGracePeriod time.Duration
+	// Ports are the ports which must all be healthy for a server to be up, each one being checked in turn.
+	// When set, they supersede the Port, the ports of the ServerOptions, and the ports advertised through the PortFromHeader.
+	Ports []int
+	// ProxyURL is the URL of the proxy the HTTP checks are sent through, with the http, https, or socks5 scheme.
+	ProxyURL string
+	// FlapThreshold is the number of state changes of a server within the FlapWindow from which the server is flapping,
+	// which is reported by a single warning per FlapWindow. There is no flap detection when zero.
+	FlapThreshold int
+	// FlapWindow is the rolling window over which the state changes of a server are counted, defaults to ten intervals.
+	FlapWindow time.Duration
+	// FlapCooldown is the duration a flapping server is held down when it goes down, whatever the result of its checks.
+	FlapCooldown time.Duration
+	// MinCertLifetime is the minimum remaining lifetime of the certificate chain presented by the servers to the HTTPS checks,
+	// below which the servers are degraded, even though their response is healthy. There is no lifetime check when zero.
+	MinCertLifetime time.Duration
+	// MinCertLifetimeDown makes the servers whose certificate chain expires within the MinCertLifetime down, instead of degraded.
+	MinCertLifetimeDown bool
+	// HeadFallbackGet makes the HTTP checks with the HEAD method retry once with the GET method
+	// when the server responds with the 405 or 501 status code, for the servers not implementing HEAD.
+	HeadFallbackGet bool
+	// ParallelChecks is the maximum number of servers of the backend checked at the same time on each Interval,
+	// the servers being checked one at a time when it is lower than two.
+	// The load-balancer is only updated once all the checks of the round are over.
+	// With parallel checks, the AuthTokenFunc, SignRequest, and Evaluate functions may be called concurrently.
+	ParallelChecks int
+	// ExpectedALPN is the application protocol the HTTPS checks must negotiate with the servers through ALPN, e.g. h2.
+	// The checks only offer h2 with the HTTP2 option.
+	ExpectedALPN string
+	// SlowThreshold is the duration beyond which a healthy response of an HTTP check, including its body, is too slow,
+	// degrading the server until it responds faster again. There is no latency check when zero.
+	SlowThreshold time.Duration
+	// CookieJar holds the cookies of the HTTP checks, sent back to the servers across redirects and between intervals,
+	// e.g. for the health endpoints requiring a session cookie set by a login redirect.
+	// When set, it supersedes the cookie jar of the HTTPClient.
+	CookieJar http.CookieJar
+	// OnBackendStateChange is called when the backend goes from having all its servers up to having some of them down,
+	// with allHealthy set to false, and back, with allHealthy set to true, once per transition.
+	OnBackendStateChange func(backendName string, allHealthy bool)
+	// SidecarAddress is the host:port address the HTTP checks are sent to instead of the address of the server,
+	// e.g. for a health endpoint served by a service mesh sidecar, while their Host header and path stay the ones of the server.
+	// It may hold the {host}, {port}, and {scheme} tokens of the server URL, e.g. {host}:15021. The Ports do not apply to it.
+	SidecarAddress string
+	// RampUpDuration is the duration over which the weight of a server returning to the load-balancer increases linearly,
+	// from 1 to its full weight, so that a cold server is not sent its full share of the traffic at once. There is no ramp-up when zero.
+	RampUpDuration time.Duration
+	// ExpectedHeaders are the headers the HTTP check responses must hold, with the given values, for the server to be healthy.
+	// An empty value only requires the header to be present, whatever its value.
+	ExpectedHeaders map[string]string
+	// LocalAddr is the local IP address the connections of the HTTP, TCP, and gRPC checks originate from,
+	// e.g. on a multi-homed host, for the servers only accepting the connections of a given network.
+	LocalAddr string
+	// SampleFraction is the fraction of the servers checked on each cycle, e.g. to reduce the load of the checks of the large backends,
+	// at the expense of a slower detection: each cycle checks the servers not checked for the longest time,
+	// so that all the servers are checked over ceil(1/SampleFraction) cycles. All the servers are checked on each cycle when zero.
+	SampleFraction float64
+	// Registry is the external service registry the servers are deregistered from when the health check removes them from the load-balancer,
+	// and registered again in once returned. There is none when nil.
+	Registry Registry
+	// GRPCDialTarget is the target the gRPC checks dial instead of the address of the server, e.g. unix:///var/run/app.sock,
+	// dns:///app.internal:50051, or an xds target. It may hold the {host}, {port}, and {scheme} tokens of the server URL.
+	GRPCDialTarget string
+	// GRPCDialOptions are the options of the connections of the gRPC checks, on top of the ones derived from the Scheme and the TLSConfig,
+	// e.g. for the credentials or the resolver of a mesh.
+	GRPCDialOptions []grpc.DialOption
+	// MaxClockSkew is the maximum difference between the Date header of the HTTP check responses and the local time,
+	// beyond which the clock of the server is skewed, e.g. breaking the time-based authentication, and the server degraded.
+	// There is no clock skew check when zero.
+	MaxClockSkew time.Duration
+	// MaxClockSkewDown makes the servers whose clock is skewed beyond the MaxClockSkew down, instead of degraded.
+	MaxClockSkewDown bool
+	// ReResolve makes the HTTP checks resolve the hostname of the servers on each check, without reusing their connections,
+	// so that they follow the changes of the DNS records instead of staying connected to a former address.
+	ReResolve bool
+	// MaxProbeDuration bounds the whole HTTP check of a server, including its candidate paths, fallbacks, and redirects,
+	// and the read of the response bodies, while the Timeout bounds each of its requests.
+	// A check running beyond it is aborted, and the server is down. There is no bound when zero.
+	MaxProbeDuration time.Duration
+	// SendString is written to the connections of the TCP checks once established, e.g. a PING command.
+	SendString string
+	// ExpectString is a substring the response of the servers to the TCP checks must contain, within the Timeout, for the servers to be healthy,
+	// e.g. the banner of an SMTP server. Only the first maxBodySize bytes of the response are matched.
+	ExpectString string
+	// TreatResetAsHealthy makes the servers resetting the connections of their checks healthy, instead of down,
+	// e.g. the servers draining their connections before a restart, which stay in the load-balancer meanwhile.
+	TreatResetAsHealthy bool
+	// ResetDegraded makes the servers resetting the connections of their checks degraded, instead of healthy, with TreatResetAsHealthy.
+	ResetDegraded bool
+	// MinHealthyServers is the number of servers below which the servers failing their checks stay in the load-balancer,
+	// a safety valve against the correlated failures emptying it, like the KeepLastHealthy. There is no minimum when zero.
+	MinHealthyServers int
+	// ExpectedJSON are the values the fields of the JSON body of the HTTP check responses must have for the server to be healthy,
+	// by the dot-separated path of the fields, e.g. status: UP, or checks.0.healthy: true for the first element of an array.
+	// A body which is not valid JSON, e.g. beyond maxBodySize, fails the check.
+	ExpectedJSON map[string]string
+	// UnhealthyInterval is the interval between two checks of the servers removed from the load-balancer, to detect their recovery faster,
+	// while the Interval applies to the servers in the load-balancer. It only applies when shorter than the Interval.
+	UnhealthyInterval time.Duration
+	// Readiness configures an additional readiness probe, checked on its own Interval,
+	// while these options configure the liveness probe.
+	// A server failing its liveness probe is removed from the load-balancer,
+	// while a server failing its readiness probe only has its weight reduced to the DegradedWeight.
+	Readiness *Options
+	LB        Balancer
+}
+
+// ServerOptions are the health check options of a server overriding the ones of its backend,
+// the options of the backend applying to the unspecified ones.
+type ServerOptions struct {
+	// Path is the path checked, instead of the Path and Paths of the backend. Like the Path, it may hold tokens.
+	Path string
+	// Port is the port checked, instead of the Port of the backend.
+	Port int
+}
+
+// StatusEvent describes the status change of a server.
+type StatusEvent struct {
+	Backend string
+	Server  *url.URL
+	// OldStatus and NewStatus are either "UP" or "DOWN".
+	OldStatus string
+	NewStatus string
+	Time      time.Time
+	// Reason is the reason of the failure of the server, empty when it is back up.
+	Reason string
+}
+
+// redactedOptions are the options holding credentials, only reported as redacted by String.
+var redactedOptions = map[string]bool{
+	"AuthToken": true,
+	"BasicAuth": true,
 }
 
+// String reports the options set, in the order of their declaration.
+// The credentials are redacted, and the functions, clients, and other opaque values are only reported as set.
 func (opt Options) String() string {
-	return fmt.Sprintf("[Hostname: %s Headers: %v Path: %s Method: %s Port: %d Interval: %s Timeout: %s FollowRedirects: %v]", opt.Hostname, opt.Headers, opt.Path, opt.Method, opt.Port, opt.Interval, opt.Timeout, opt.FollowRedirects)
+	var b strings.Builder
+	b.WriteString("[")
+
+	value := reflect.ValueOf(opt)
+	for i := 0; i < value.NumField(); i++ {
+		name, field := value.Type().Field(i).Name, value.Field(i)
+		if name == "LB" || field.IsZero() {
+			continue
+		}
+
+		if b.Len() > 1 {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "%s: %s", name, formatOption(name, field))
+	}
+
+	b.WriteString("]")
+	return b.String()
+}
+
+func formatOption(name string, field reflect.Value) string {
+	switch {
+	case redactedOptions[name]:
+		return "<redacted>"
+	case name == "Readiness":
+		return field.Interface().(*Options).String()
+	case name == "ProxyURL":
+		proxyURL, err := url.Parse(field.String())
+		if err != nil {
+			return "<redacted>"
+		}
+		return proxyURL.Redacted()
+	}
+
+	switch field.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.Ptr:
+		return "<set>"
+	case reflect.Slice:
+		if kind := field.Type().Elem().Kind(); kind == reflect.Func || kind == reflect.Interface {
+			return "<set>"
+		}
+	}
+
+	return fmt.Sprintf("%v", field.Interface())
 }
 
 type backendURL struct {
@@ -93,262 +439,2726 @@ type backendURL struct {
 // BackendConfig HealthCheck configuration for a backend.
 type BackendConfig struct {
 	Options
-	name         string
+	name string
+	// clock is the clock of the HealthCheck checking the backend, set by SetBackendsConfiguration, the real clock when nil.
+	clock Clock
+
+	// stateMu serializes the state changes of the servers, by the rounds of checks and by the passive health check,
+	// and guards the state of the servers without a lock of its own, e.g. the ramp-ups, the degraded weights, or the flaps.
+	// It is never held while checking the servers, and is acquired before mu.
+	stateMu sync.Mutex
+	// mu guards disabledURLs and outcomes, which are also updated by ReportResult,
+	// and headerPorts, certLifetimes, and autoSchemes, which are also updated by CheckNow.
+	mu           sync.Mutex
 	disabledURLs []backendURL
+	outcomes     map[string]*outcomeWindow
+	// headerPorts holds, by server URL, the port advertised through the PortFromHeader header.
+	headerPorts map[string]int
+	// certLifetimes holds, by server URL, the remaining lifetime of the certificate chain presented on the last HTTPS check.
+	certLifetimes map[string]time.Duration
+	// autoSchemes holds, by server URL, the scheme the servers answered on, with the AutoScheme.
+	autoSchemes map[string]string
+
+	// expectedStatus holds the parsed ExpectedStatus option, nil when unset.
+	expectedStatus types.HTTPCodeRanges
+	// expectedBodyRegex holds the compiled ExpectedBodyRegex option, nil when unset.
+	expectedBodyRegex *regexp.Regexp
+
+	// consecutiveFailures and consecutiveSuccesses count, by server URL,
+	// the checks in a row which did not flip the state of the server yet.
+	consecutiveFailures  map[string]int
+	consecutiveSuccesses map[string]int
+
+	// failures counts, by server URL, the failed checks in a row, whatever the state of the server.
+	failures map[string]int
+	// degradedWeights holds, by server URL, the weight the degraded servers are restored to once healthy.
+	degradedWeights map[string]int
+	// rampUps holds, by server URL, the servers ramping up to their full weight after their return to the load-balancer.
+	rampUps map[string]*rampUp
+	// degradations holds, by server URL, the probes reporting the server as degraded.
+	degradations map[string]degradation
+	// skippedChecks holds, by server URL, the number of intervals to wait before checking the server again, guarded by mu.
+	skippedChecks map[string]int
+	// healthySkips holds, by server URL, the number of rounds of checks to wait before checking again the server in the load-balancer,
+	// with an UnhealthyInterval, guarded by mu.
+	healthySkips map[string]int
+	// nextCycle is the time of the next round of checks, guarded by mu, zero until the checks are scheduled.
+	nextCycle time.Time
+	// cycleInterval is the interval between the rounds of checks, without the jitter, guarded by mu.
+	// It is the UnhealthyInterval when shorter than the Interval.
+	cycleInterval time.Duration
+	// halfOpen holds the servers whose next check ends a backoff wait, the half-open probe.
+	halfOpen map[string]bool
+	// flaps holds, by server URL, the recent state changes of the servers, for the flap detection.
+	flaps map[string]*flapState
+	// partiallyHealthy is whether some servers of the backend were down on the last round of checks, for the OnBackendStateChange.
+	partiallyHealthy bool
+	// sampledAt holds, by server URL, the last cycle the server was checked on, with a SampleFraction.
+	sampledAt   map[string]int
+	sampleCycle int
+	// failureLogs holds, by server URL, the last failure logged for the failing servers, for the deduplication of the failure logs.
+	failureLogs map[string]*failureLog
+	// graceUntil is the end of the StartupGracePeriod, zero without one or before the first round of checks.
+	graceUntil time.Time
+	// checked is closed once the first round of checks is over, or once the checks stopped before it.
+	checked     chan struct{}
+	checkedOnce sync.Once
+
+	// basicAuthFile caches the credentials read from the BasicAuthFile.
+	basicAuthFile basicAuthFile
+
+	// readiness is the configuration of the readiness probe, nil when there is none.
+	readiness *BackendConfig
+	// grpcWatcher holds the statuses pushed by the servers through the gRPC Watch streams, nil without GRPCUseWatch.
+	grpcWatcher *grpcWatcher
+
+	rand *rand.Rand // For the interval jitter.
+}
+
+// nextInterval returns the duration to wait before the next check.
+func (b *BackendConfig) nextInterval() time.Duration {
+	if b.IntervalJitter <= 0 {
+		return b.tickInterval()
+	}
+
+	return b.tickInterval() + time.Duration(b.rand.Int63n(int64(b.IntervalJitter)+1))
+}
+
+// countFailures records the outcome of a check of the given server,
+// and returns the number of checks in a row which failed.
+func (b *BackendConfig) countFailures(u *url.URL, failed bool) int {
+	key := u.String()
+	if !failed {
+		delete(b.failures, key)
+		return 0
+	}
+
+	if b.failures == nil {
+		b.failures = make(map[string]int)
+	}
+	b.failures[key]++
+
+	return b.failures[key]
+}
+
+// recordFailure records a failed check of the given server,
+// and returns whether the failure threshold is reached.
+func (b *BackendConfig) recordFailure(u *url.URL) bool {
+	key := u.String()
+	delete(b.consecutiveSuccesses, key)
+
+	if b.consecutiveFailures == nil {
+		b.consecutiveFailures = make(map[string]int)
+	}
+	b.consecutiveFailures[key]++
+
+	if b.consecutiveFailures[key] < threshold(b.FailThreshold) {
+		return false
+	}
+
+	delete(b.consecutiveFailures, key)
+	return true
+}
+
+// recordSuccess records a successful check of the given server,
+// and returns whether the rise threshold is reached.
+func (b *BackendConfig) recordSuccess(u *url.URL) bool {
+	key := u.String()
+	delete(b.consecutiveFailures, key)
+
+	if b.consecutiveSuccesses == nil {
+		b.consecutiveSuccesses = make(map[string]int)
+	}
+	b.consecutiveSuccesses[key]++
+
+	if b.consecutiveSuccesses[key] < threshold(b.RiseThreshold) {
+		return false
+	}
+
+	delete(b.consecutiveSuccesses, key)
+	return true
+}
+
+// threshold returns the given threshold, defaulting to a single check.
+func threshold(value int) int {
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// CheckURL returns the URL the HTTP checks of the given server target, i.e. with the scheme, port, and path of the checks.
+func (b *BackendConfig) CheckURL(serverURL *url.URL) (*url.URL, error) {
+	req, err := b.newRequest(serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return req.URL, nil
 }
 
 func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
-	u, err := serverURL.Parse(b.Path)
+	return b.newPathRequest(serverURL, b.path(serverURL))
+}
+
+// newRequests returns the requests of an HTTP check, one per candidate path.
+func (b *BackendConfig) newRequests(serverURL *url.URL) ([]*http.Request, error) {
+	paths := b.Paths
+	if len(paths) == 0 || b.ServerOptions[serverURL.String()].Path != "" {
+		paths = []string{b.path(serverURL)}
+	}
+
+	reqs := make([]*http.Request, 0, len(paths))
+	for _, path := range paths {
+		req, err := b.newPathRequest(serverURL, path)
+		if err != nil {
+			return nil, err
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	return reqs, nil
+}
+
+// path returns the path checked on the given server.
+func (b *BackendConfig) path(serverURL *url.URL) string {
+	if path := b.ServerOptions[serverURL.String()].Path; path != "" {
+		return path
+	}
+	return b.Path
+}
+
+// port returns the port checked on the given server, zero for the port of the server URL.
+func (b *BackendConfig) port(serverURL *url.URL) int {
+	if b.PortFromHeader != "" {
+		b.mu.Lock()
+		port := b.headerPorts[serverURL.String()]
+		b.mu.Unlock()
+
+		if port != 0 {
+			return port
+		}
+	}
+
+	if port := b.ServerOptions[serverURL.String()].Port; port != 0 {
+		return port
+	}
+	return b.Port
+}
+
+// recordHeaderPort remembers the port advertised by the given response of the server through the PortFromHeader header, if any.
+func (b *BackendConfig) recordHeaderPort(serverURL *url.URL, resp *http.Response) {
+	if b.PortFromHeader == "" {
+		return
+	}
+
+	value := resp.Header.Get(b.PortFromHeader)
+	if value == "" {
+		return
+	}
+
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		log.WithoutContext().Debugf("Ignoring the invalid port %q advertised by the server %s", value, serverURL.String())
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.headerPorts == nil {
+		b.headerPorts = make(map[string]int)
+	}
+	b.headerPorts[serverURL.String()] = port
+}
+
+// httpTarget returns the address dialed by the HTTP checks of the given server, and the Host header they send,
+// which may differ when a Hostname is configured. Both are empty for the other check modes.
+func (b *BackendConfig) httpTarget(serverURL *url.URL) (string, string) {
+	switch b.Mode {
+	case GRPCMode, TCPMode, UDPMode, DNSMode:
+		return "", ""
+	}
+
+	req, err := b.newRequest(serverURL)
+	if err != nil {
+		return "", ""
+	}
+
+	host := req.Host
+	if b.Hostname != "" {
+		host = b.Hostname
+	}
+
+	if b.UnixSocket != "" {
+		return b.UnixSocket, host
+	}
+
+	address := req.URL.Host
+	if req.URL.Port() == "" {
+		port := "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(req.URL.Hostname(), port)
+	}
+
+	return address, host
+}
+
+func (b *BackendConfig) newPathRequest(serverURL *url.URL, path string) (*http.Request, error) {
+	u, err := serverURL.Parse(expandPath(path, serverURL))
+	if err != nil {
+		return nil, err
+	}
+
+	if scheme := b.scheme(serverURL); scheme != "" {
+		u.Scheme = scheme
+	}
+
+	if port := b.port(serverURL); port != 0 {
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	}
+
+	if b.UnixSocket != "" {
+		// The connections are routed to the socket by the transport.
+		u.Host = unixSocketHost
+	}
+
+	var host string
+	if b.SidecarAddress != "" {
+		// The request is sent to the sidecar, on behalf of the server.
+		host = u.Host
+		u.Host = expandAddress(b.SidecarAddress, serverURL)
+	}
+
+	var body io.Reader = http.NoBody
+	if b.Body != "" {
+		switch strings.ToUpper(b.Method) {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			// The request built from a strings.Reader can be replayed, e.g. on redirects, as it sets the GetBody function.
+			body = strings.NewReader(b.Body)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(b.Scheme) > 0 {
-		u.Scheme = b.Scheme
+	if host != "" {
+		req.Host = host
+	}
+
+	return req, nil
+}
+
+// setRequestOptions sets all request options present on the BackendConfig.
+func (b *BackendConfig) setRequestOptions(req *http.Request) (*http.Request, error) {
+	if b.Options.Hostname != "" {
+		req.Host = b.Options.Hostname
+	}
+
+	userAgent := b.Options.UserAgent
+	if userAgent == "" {
+		userAgent = "Traefik-HealthCheck/" + version.Version
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	for k, v := range b.Options.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if b.Options.Method != "" {
+		req.Method = strings.ToUpper(b.Options.Method)
+	}
+
+	token := b.Options.AuthToken
+	if b.Options.AuthTokenFunc != nil {
+		var err error
+		token, err = b.Options.AuthTokenFunc()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the authorization token: %w", err)
+		}
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	basicAuth := b.Options.BasicAuth
+	if b.Options.BasicAuthFile != "" {
+		var err error
+		basicAuth, err = b.basicAuthFile.credentials(b.Options.BasicAuthFile, b.getClock().Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the basic auth file: %w", err)
+		}
+	}
+
+	if basicAuth != nil {
+		req.SetBasicAuth(basicAuth.Username, basicAuth.Password)
+	}
+
+	if b.Options.SignRequest != nil {
+		if err := b.Options.SignRequest(req); err != nil {
+			return nil, fmt.Errorf("%w: failed to sign the request: %v", errConfiguration, err)
+		}
+	}
+
+	return req, nil
+}
+
+// HealthCheck struct.
+type HealthCheck struct {
+	Backends map[string]*BackendConfig
+	metrics  metricsHealthcheck
+	cancel   context.CancelFunc
+	probes   probeRegistry
+	// running tracks the goroutines checking the backends, including the ones of the previous configurations.
+	running sync.WaitGroup
+
+	// slots bounds the number of probes running concurrently, across all the backends.
+	slotsMu sync.Mutex
+	slots   chan struct{}
+
+	// suspended holds the names of the backends whose checks are suspended.
+	suspendedMu sync.RWMutex
+	suspended   map[string]struct{}
+
+	// intervalMu guards the intervalMultiplier, and the intervalChanged channel closed on each of its changes.
+	intervalMu         sync.Mutex
+	intervalMultiplier float64
+	intervalChanged    chan struct{}
+
+	// backendsMu guards the Backends, which are also read by the StatusHandler, and the clock.
+	backendsMu sync.RWMutex
+	// clock paces the checks of the backends, the real clock when nil.
+	clock Clock
+}
+
+// SetEnabled suspends or resumes the checks of the given backend.
+// While suspended, the servers of the backend keep their last state.
+func (hc *HealthCheck) SetEnabled(backendName string, enabled bool) {
+	hc.suspendedMu.Lock()
+	defer hc.suspendedMu.Unlock()
+
+	if enabled {
+		delete(hc.suspended, backendName)
+		return
+	}
+
+	if hc.suspended == nil {
+		hc.suspended = make(map[string]struct{})
+	}
+	hc.suspended[backendName] = struct{}{}
+}
+
+// isSuspended returns whether the checks of the given backend are suspended.
+func (hc *HealthCheck) isSuspended(backendName string) bool {
+	hc.suspendedMu.RLock()
+	defer hc.suspendedMu.RUnlock()
+
+	_, ok := hc.suspended[backendName]
+	return ok
+}
+
+// SetClock sets the clock pacing the checks of the backends, e.g. to drive the intervals in tests.
+// It applies to the backends configured afterwards.
+func (hc *HealthCheck) SetClock(clock Clock) {
+	hc.backendsMu.Lock()
+	defer hc.backendsMu.Unlock()
+
+	hc.clock = clock
+}
+
+// getClock returns the clock of the HealthCheck checking the backend.
+func (b *BackendConfig) getClock() Clock {
+	if b.clock == nil {
+		return realClock{}
+	}
+	return b.clock
+}
+
+// getClock returns the clock pacing the checks of the backends.
+func (hc *HealthCheck) getClock() Clock {
+	hc.backendsMu.RLock()
+	defer hc.backendsMu.RUnlock()
+
+	if hc.clock == nil {
+		return realClock{}
+	}
+	return hc.clock
+}
+
+// SetMaxConcurrentProbes limits the number of probes running concurrently across all the backends.
+// There is no limit when max is zero or negative.
+func (hc *HealthCheck) SetMaxConcurrentProbes(max int) {
+	hc.slotsMu.Lock()
+	defer hc.slotsMu.Unlock()
+
+	if max <= 0 {
+		hc.slots = nil
+		return
+	}
+
+	hc.slots = make(chan struct{}, max)
+}
+
+// SetGlobalInterval scales the intervals of the checks of all the backends by the given multiplier at runtime,
+// e.g. to reduce the load of the checks during an incident, while the servers keep their state.
+// The intervals are restored with a multiplier of 1, or one which is not positive.
+func (hc *HealthCheck) SetGlobalInterval(multiplier float64) {
+	hc.intervalMu.Lock()
+	defer hc.intervalMu.Unlock()
+
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+	hc.intervalMultiplier = multiplier
+
+	if hc.intervalChanged != nil {
+		close(hc.intervalChanged)
+	}
+	hc.intervalChanged = make(chan struct{})
+}
+
+// globalInterval returns the multiplier of the intervals of the checks, and a channel closed on its next change.
+func (hc *HealthCheck) globalInterval() (float64, <-chan struct{}) {
+	hc.intervalMu.Lock()
+	defer hc.intervalMu.Unlock()
+
+	if hc.intervalChanged == nil {
+		hc.intervalChanged = make(chan struct{})
+	}
+
+	if hc.intervalMultiplier <= 0 {
+		return 1, hc.intervalChanged
+	}
+	return hc.intervalMultiplier, hc.intervalChanged
+}
+
+// scaleInterval returns the given interval scaled by the given multiplier.
+func scaleInterval(interval time.Duration, multiplier float64) time.Duration {
+	scaled := time.Duration(float64(interval) * multiplier)
+	if scaled <= 0 {
+		// The multiplier is too small for the interval, which is kept, as the tickers need a positive interval.
+		return interval
+	}
+	return scaled
+}
+
+// SetBackendsConfiguration set backends configuration.
+// The checks of the former backends are stopped before they are replaced, so that they never update the servers concurrently with the new ones.
+func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backends map[string]*BackendConfig) {
+	stopCtx, stopCancel := context.WithTimeout(parentCtx, StopTimeout)
+	if err := hc.Stop(stopCtx); err != nil {
+		log.FromContext(parentCtx).Warnf("Replacing the health checked backends: %v", err)
+	}
+	stopCancel()
+
+	// The clock is set before the backends are published, for the passive health check reading them concurrently.
+	clock := hc.getClock()
+	for _, backend := range backends {
+		backend.clock = clock
+		if backend.readiness != nil {
+			backend.readiness.clock = clock
+		}
+	}
+
+	hc.backendsMu.Lock()
+	hc.Backends = backends
+	hc.backendsMu.Unlock()
+
+	hc.probes.reset()
+	ctx, cancel := context.WithCancel(parentCtx)
+	hc.cancel = cancel
+
+	for _, backend := range backends {
+		currentBackend := backend
+		hc.running.Add(1)
+		safe.Go(func() {
+			defer hc.running.Done()
+			hc.execute(ctx, currentBackend)
+		})
+	}
+}
+
+// Stop stops checking the backends, and waits for the running checks to return,
+// at the latest until the given context is done.
+func (hc *HealthCheck) Stop(ctx context.Context) error {
+	if hc.cancel != nil {
+		hc.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		hc.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for the health checks to stop: %w", ctx.Err())
+	}
+}
+
+func (hc *HealthCheck) execute(ctx context.Context, backend *BackendConfig) {
+	logger := log.FromContext(ctx)
+	clock := hc.getClock()
+	defer backend.markChecked()
+
+	if backend.InitialDelay > 0 {
+		logger.Debugf("Delaying the initial health check for backend %q by %s", backend.name, backend.InitialDelay)
+
+		timer := clock.NewTimer(backend.InitialDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Debugf("Stopping current health check goroutines of backend: %s", backend.name)
+			return
+		case <-timer.C():
+		}
+	}
+
+	// The statuses pushed by the servers trigger a round of checks.
+	var watchChanges <-chan struct{}
+	if backend.grpcWatcher != nil {
+		hc.watchServers(ctx, backend)
+		watchChanges = backend.grpcWatcher.changes
+	}
+
+	if hc.isSuspended(backend.name) {
+		logger.Debugf("Health check suspended for backend: %q", backend.name)
+	} else {
+		logger.Debugf("Initial health check for backend: %q", backend.name)
+		hc.checkCycle(ctx, backend)
+	}
+
+	multiplier, intervalChanges := hc.globalInterval()
+	scheduleCycle := func(next time.Time) {
+		backend.scheduleCycle(next, scaleInterval(backend.tickInterval(), multiplier))
+	}
+
+	// The readiness checks run in the same goroutine, so that they never update the weight of a server concurrently with the liveness checks.
+	var readinessTicker Ticker
+	var readinessTicks <-chan time.Time
+	if backend.readiness != nil {
+		if !hc.isSuspended(backend.name) {
+			hc.checkReadiness(ctx, backend)
+		}
+
+		readinessTicker = clock.NewTicker(scaleInterval(backend.readiness.Interval, multiplier))
+		defer readinessTicker.Stop()
+		readinessTicks = readinessTicker.C()
+	}
+
+	interval := scaleInterval(backend.nextInterval(), multiplier)
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+	scheduleCycle(clock.Now().Add(interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debugf("Stopping current health check goroutines of backend: %s", backend.name)
+			return
+		case <-readinessTicks:
+			if !hc.isSuspended(backend.name) {
+				hc.checkReadiness(ctx, backend)
+			}
+		case <-watchChanges:
+			if !hc.isSuspended(backend.name) {
+				logger.Debugf("gRPC status pushed for backend: %s", backend.name)
+				hc.checkCycle(ctx, backend)
+			}
+		case <-intervalChanges:
+			// The next round of checks is rescheduled at once, rather than on the former interval.
+			multiplier, intervalChanges = hc.globalInterval()
+			logger.Debugf("Health check intervals scaled by %v for backend: %s", multiplier, backend.name)
+
+			interval = scaleInterval(backend.nextInterval(), multiplier)
+			ticker.Reset(interval)
+			scheduleCycle(clock.Now().Add(interval))
+
+			if readinessTicker != nil {
+				readinessTicker.Reset(scaleInterval(backend.readiness.Interval, multiplier))
+			}
+		case tick := <-ticker.C():
+			if hc.isSuspended(backend.name) {
+				logger.Debugf("Health check suspended for backend: %s", backend.name)
+				scheduleCycle(tick.Add(interval))
+				continue
+			}
+
+			logger.Debugf("Routine health check refresh for backend: %s", backend.name)
+			hc.checkCycle(ctx, backend)
+
+			// The jitter is drawn again for each interval, so that the checks keep spreading over time.
+			if backend.IntervalJitter > 0 {
+				interval = scaleInterval(backend.nextInterval(), multiplier)
+				ticker.Reset(interval)
+				scheduleCycle(clock.Now().Add(interval))
+				continue
+			}
+			scheduleCycle(tick.Add(interval))
+		}
+	}
+}
+
+// checkCycle checks all the servers of the backend, and records the duration of this round of checks,
+// which exceeds the Interval when the checks cannot keep up with it.
+func (hc *HealthCheck) checkCycle(ctx context.Context, backend *BackendConfig) {
+	clock := hc.getClock()
+	start := clock.Now()
+	hc.checkServersLB(ctx, backend)
+	backend.markChecked()
+
+	if hc.metrics.checkCycleDuration != nil {
+		hc.metrics.checkCycleDuration.With("service", backend.name).Observe(clock.Now().Sub(start).Seconds())
+	}
+
+	backend.notifyStateChange()
+}
+
+// Checked returns a channel closed once the first round of checks of the backend is over,
+// so that the statuses of its servers reflect their health, or once its checks stopped before it.
+func (b *BackendConfig) Checked() <-chan struct{} {
+	return b.checked
+}
+
+func (b *BackendConfig) markChecked() {
+	if b.checked == nil {
+		return
+	}
+
+	b.checkedOnce.Do(func() { close(b.checked) })
+}
+
+// notifyStateChange calls the OnBackendStateChange when the backend went from having all its servers up to having some of them down,
+// or back, since the last round of checks.
+func (b *BackendConfig) notifyStateChange() {
+	if b.OnBackendStateChange == nil {
+		return
+	}
+
+	partiallyHealthy := b.DisabledCount() > 0
+	if partiallyHealthy == b.partiallyHealthy {
+		return
+	}
+
+	b.partiallyHealthy = partiallyHealthy
+	b.OnBackendStateChange(b.name, !partiallyHealthy)
+}
+
+func (hc *HealthCheck) checkServersLB(ctx context.Context, backend *BackendConfig) {
+	logger := log.FromContext(ctx)
+	now := hc.getClock().Now()
+
+	backend.stateMu.Lock()
+	// The grace period is counted with the clock pacing the checks.
+	if backend.StartupGracePeriod > 0 && backend.graceUntil.IsZero() {
+		backend.graceUntil = now.Add(backend.StartupGracePeriod)
+	}
+	backend.stateMu.Unlock()
+
+	backend.mu.Lock()
+	disabledURLs := backend.disabledURLs
+	backend.mu.Unlock()
+
+	enabledURLs := backend.LB.Servers()
+	if backend.ShadowMode {
+		// In shadow mode, the servers are never removed from the load-balancer,
+		// the ones which would have been removed are only tracked as disabled.
+		enabledURLs = withoutDisabledURLs(enabledURLs, disabledURLs)
+	}
+
+	var checkedURLs []*url.URL
+	for _, disabledURL := range disabledURLs {
+		if !backend.skipCheck(disabledURL.url) && !backend.heldDown(disabledURL.url, now) {
+			checkedURLs = append(checkedURLs, disabledURL.url)
+		}
+	}
+	for _, enabledURL := range enabledURLs {
+		if !backend.skipCheck(enabledURL) && !backend.skipHealthy(enabledURL) {
+			checkedURLs = append(checkedURLs, enabledURL)
+		}
+	}
+
+	results := hc.checkServers(ctx, backend, backend.sample(checkedURLs))
+	if ctx.Err() != nil {
+		// The checks were aborted by Stop, their failures do not reflect the health of the servers.
+		return
+	}
+
+	backend.stateMu.Lock()
+	defer backend.stateMu.Unlock()
+
+	var newDisabledURLs []backendURL
+	for _, disabledURL := range disabledURLs {
+		err, checked := results[disabledURL.url.String()]
+		if !checked {
+			newDisabledURLs = append(newDisabledURLs, disabledURL)
+			continue
+		}
+
+		serverUpMetricValue := float64(0)
+
+		if errors.Is(err, errProbeSkipped) {
+			logger.Warnf("Health check skipped, too many probes in flight. Backend: %q URL: %q", backend.name, disabledURL.url.String())
+			newDisabledURLs = append(newDisabledURLs, disabledURL)
+			continue
+		}
+		recordCheck(backend, disabledURL.url, err)
+
+		degraded := errors.Is(err, errDegraded)
+		if degraded {
+			err = nil
+		}
+
+		if err == nil {
+			backend.logRecovery(ctx, disabledURL.url)
+		}
+
+		switch {
+		case err != nil:
+			delete(backend.consecutiveSuccesses, disabledURL.url.String())
+
+			if backend.logFailure(disabledURL.url, err) {
+				logger.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s (%s)", backend.name, disabledURL.url.String(), err, classifyFailure(err))
+			}
+			newDisabledURLs = append(newDisabledURLs, disabledURL)
+
+		case !backend.closeHalfOpen(disabledURL.url) && !backend.recordSuccess(disabledURL.url):
+			logger.Debugf("Health check up, waiting for %d consecutive successes before returning to server list. Backend: %q URL: %q",
+				threshold(backend.RiseThreshold), backend.name, disabledURL.url.String())
+			newDisabledURLs = append(newDisabledURLs, disabledURL)
+
+		case backend.ShadowMode:
+			logger.Warnf("Shadow health check up: would return to server list. Backend: %q URL: %q Weight: %d",
+				backend.name, disabledURL.url.String(), disabledURL.weight)
+			serverUpMetricValue = 1
+			backend.detectFlap(ctx, disabledURL.url, false, now)
+
+		default:
+			weight := disabledURL.weight
+			if backend.setDegraded(disabledURL.url, degradedByLiveness, degraded) {
+				weight = backend.degrade(disabledURL.url, weight)
+			} else {
+				weight = backend.startRampUp(disabledURL.url, weight, now)
+			}
+
+			logger.Warnf("Health check up: returning to server list. Backend: %q URL: %q Weight: %d",
+				backend.name, disabledURL.url.String(), weight)
+			if err := backend.LB.UpsertServer(disabledURL.url, roundrobin.Weight(weight)); err != nil {
+				logger.Error(err)
+			}
+			backend.publish(disabledURL.url, serverDown, serverUp, "")
+			backend.updateRegistry(ctx, disabledURL.url, true)
+			backend.delayHealthy(disabledURL.url)
+			serverUpMetricValue = 1
+			backend.detectFlap(ctx, disabledURL.url, false, now)
+		}
+
+		labelValues := []string{"service", backend.name, "url", disabledURL.url.String()}
+		hc.metrics.serverUpGauge.With(labelValues...).Set(serverUpMetricValue)
+		failures := hc.setServerFailures(backend, disabledURL.url, err != nil, labelValues)
+		backend.backoff(disabledURL.url, failures)
+		if serverUpMetricValue == 0 {
+			backend.retryAfter(disabledURL.url, err)
+		}
+	}
+
+	backend.mu.Lock()
+	// The servers disabled by the passive health check in the meantime stay disabled.
+	backend.disabledURLs = append(newDisabledURLs, backend.disabledURLs[len(disabledURLs):]...)
+	backend.mu.Unlock()
+
+	for _, enabledURL := range enabledURLs {
+		err, checked := results[enabledURL.String()]
+		if !checked {
+			continue
+		}
+
+		serverUpMetricValue := float64(1)
+
+		if errors.Is(err, errProbeSkipped) {
+			logger.Warnf("Health check skipped, too many probes in flight. Backend: %q URL: %q", backend.name, enabledURL.String())
+			continue
+		}
+		recordCheck(backend, enabledURL, err)
+
+		degraded := errors.Is(err, errDegraded)
+		if degraded {
+			err = nil
+		}
+
+		switch {
+		case err == nil:
+			delete(backend.consecutiveFailures, enabledURL.String())
+			backend.logRecovery(ctx, enabledURL)
+
+			if !backend.ShadowMode {
+				backend.updateDegradedWeight(ctx, enabledURL, backend.setDegraded(enabledURL, degradedByLiveness, degraded))
+				backend.stepRampUp(ctx, enabledURL, now)
+			}
+
+		case !backend.recordFailure(enabledURL):
+			if backend.logFailure(enabledURL, err) {
+				logger.Warnf("Health check failed, waiting for %d consecutive failures before removing from server list. Backend: %q URL: %q Reason: %s (%s)",
+					threshold(backend.FailThreshold), backend.name, enabledURL.String(), err, classifyFailure(err))
+			}
+
+		case backend.keepReason(now) != "":
+			serverUpMetricValue = 0
+
+			if backend.logFailure(enabledURL, err) {
+				logger.Warnf("Health check failed, keeping the server in the server list %s. Backend: %q URL: %q Reason: %s (%s)",
+					backend.keepReason(now), backend.name, enabledURL.String(), err, classifyFailure(err))
+			}
+
+		default:
+			serverUpMetricValue = 0
+
+			weight, disabled := backend.failServer(ctx, enabledURL, now, err.Error())
+			if !disabled {
+				// Already removed by the passive health check.
+				break
+			}
+
+			// A state change is always logged, and the next identical failures are suppressed.
+			backend.logFailure(enabledURL, err)
+
+			if backend.ShadowMode {
+				logger.Warnf("Shadow health check failed, would remove from server list. Backend: %q URL: %q Weight: %d Reason: %s (%s)",
+					backend.name, enabledURL.String(), weight, err, classifyFailure(err))
+			} else {
+				logger.Warnf("Health check failed, removing from server list. Backend: %q URL: %q Weight: %d Reason: %s (%s)",
+					backend.name, enabledURL.String(), weight, err, classifyFailure(err))
+			}
+		}
+
+		labelValues := []string{"service", backend.name, "url", enabledURL.String()}
+		hc.metrics.serverUpGauge.With(labelValues...).Set(serverUpMetricValue)
+		failures := hc.setServerFailures(backend, enabledURL, err != nil, labelValues)
+		backend.backoff(enabledURL, failures)
+		if serverUpMetricValue == 0 {
+			backend.retryAfter(enabledURL, err)
+		}
+	}
+
+	hc.setHealthyRatio(backend)
+	hc.setDisabledServers(backend)
+}
+
+// checkServers checks the given servers of the backend, up to ParallelChecks at a time, and returns the results by server URL.
+func (hc *HealthCheck) checkServers(ctx context.Context, backend *BackendConfig, servers []*url.URL) map[string]error {
+	results := make(map[string]error, len(servers))
+
+	if backend.ParallelChecks < 2 {
+		for _, server := range servers {
+			results[server.String()] = hc.checkHealth(ctx, server, backend)
+		}
+		return results
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, backend.ParallelChecks)
+	for _, server := range servers {
+		serverURL := server
+		workers <- struct{}{}
+		wg.Add(1)
+		safe.Go(func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			err := hc.checkHealth(ctx, serverURL, backend)
+
+			mu.Lock()
+			results[serverURL.String()] = err
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+// checkReadiness runs the readiness probe of the servers in the load-balancer,
+// reducing the weight of the ones which are not ready, and restoring the weight of the ones ready again.
+func (hc *HealthCheck) checkReadiness(ctx context.Context, backend *BackendConfig) {
+	logger := log.FromContext(ctx)
+
+	backend.mu.Lock()
+	disabledURLs := backend.disabledURLs
+	backend.mu.Unlock()
+
+	// The servers down are only checked by the liveness probe.
+	for _, u := range withoutDisabledURLs(backend.LB.Servers(), disabledURLs) {
+		err := checkHealth(ctx, u, backend.readiness)
+		if ctx.Err() != nil {
+			// The check was aborted by Stop.
+			return
+		}
+
+		notReady := err != nil && !errors.Is(err, errDegraded)
+		if notReady {
+			logger.Debugf("Readiness check failed. Backend: %q URL: %q Reason: %s (%s)", backend.name, u.String(), err, classifyFailure(err))
+		}
+
+		backend.setReady(ctx, u, !notReady)
+	}
+}
+
+// setReady records whether the given server is ready, and updates its weight accordingly,
+// unless it was disabled in the meantime, e.g. by the passive health check.
+func (b *BackendConfig) setReady(ctx context.Context, u *url.URL, ready bool) {
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+
+	b.mu.Lock()
+	disabled := b.isDisabled(u)
+	b.mu.Unlock()
+	if disabled {
+		return
+	}
+
+	degraded := b.setDegraded(u, degradedByReadiness, !ready)
+	if !b.ShadowMode {
+		b.updateDegradedWeight(ctx, u, degraded)
+	}
+}
+
+// setHealthyRatio reports the fraction of the servers of the backend which are up,
+// even when no server changed state, so that the reported value never goes stale.
+func (hc *HealthCheck) setHealthyRatio(backend *BackendConfig) {
+	if hc.metrics.healthyRatioGauge == nil {
+		return
+	}
+
+	backend.mu.Lock()
+	down := len(backend.disabledURLs)
+	backend.mu.Unlock()
+
+	up := len(backend.LB.Servers())
+	if backend.ShadowMode {
+		// In shadow mode, the disabled servers are still in the load-balancer.
+		up -= down
+	}
+
+	var ratio float64
+	if up+down > 0 {
+		ratio = float64(up) / float64(up+down)
+	}
+
+	hc.metrics.healthyRatioGauge.With("service", backend.name).Set(ratio)
+}
+
+// setDisabledServers reports the number of servers of the backend which are disabled,
+// even when no server changed state, so that the reported value never goes stale.
+func (hc *HealthCheck) setDisabledServers(backend *BackendConfig) {
+	if hc.metrics.disabledServersGauge == nil {
+		return
+	}
+
+	hc.metrics.disabledServersGauge.With("service", backend.name).Set(float64(backend.DisabledCount()))
+}
+
+// setServerFailures records the outcome of a check of the given server,
+// and reports and returns the number of checks in a row which failed.
+func (hc *HealthCheck) setServerFailures(backend *BackendConfig, u *url.URL, failed bool, labelValues []string) int {
+	failures := backend.countFailures(u, failed)
+
+	if hc.metrics.serverFailuresGauge != nil {
+		hc.metrics.serverFailuresGauge.With(labelValues...).Set(float64(failures))
+	}
+
+	return failures
+}
+
+// backoff schedules the next check of the given server according to its number of failed checks in a row,
+// doubling the interval between two checks on each failure, up to the BackoffMaxInterval.
+func (b *BackendConfig) backoff(u *url.URL, failures int) {
+	if b.BackoffMaxInterval <= 0 || b.Interval <= 0 {
+		return
+	}
+
+	// The half-open probe is over, whatever its outcome.
+	delete(b.halfOpen, u.String())
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if failures == 0 {
+		delete(b.skippedChecks, u.String())
+		return
+	}
+
+	intervals := 1
+	for i := 1; i < failures && time.Duration(intervals*2)*b.tickInterval() <= b.BackoffMaxInterval; i++ {
+		intervals *= 2
+	}
+
+	if intervals == 1 {
+		return
+	}
+
+	if b.skippedChecks == nil {
+		b.skippedChecks = make(map[string]int)
+	}
+	b.skippedChecks[u.String()] = intervals - 1
+}
+
+// retryAfter delays the next check of the given server, which is down,
+// until the time requested by the Retry-After header of the response which failed the check, if any.
+func (b *BackendConfig) retryAfter(u *url.URL, err error) {
+	var statusErr *statusCodeError
+	if b.Interval <= 0 || !errors.As(err, &statusErr) || statusErr.retryAfter <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The next check happens on the first interval which is not sooner than the requested time.
+	intervals := int((statusErr.retryAfter + b.tickInterval() - 1) / b.tickInterval())
+	if intervals-1 <= b.skippedChecks[u.String()] {
+		return
+	}
+
+	if b.skippedChecks == nil {
+		b.skippedChecks = make(map[string]int)
+	}
+	b.skippedChecks[u.String()] = intervals - 1
+}
+
+// skipCheck returns whether the check of the given server is skipped for the current interval, because of its backoff.
+func (b *BackendConfig) skipCheck(u *url.URL) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := u.String()
+	if b.skippedChecks[key] == 0 {
+		return false
+	}
+
+	b.skippedChecks[key]--
+	if b.skippedChecks[key] == 0 {
+		delete(b.skippedChecks, key)
+
+		if b.halfOpen == nil {
+			b.halfOpen = make(map[string]bool)
+		}
+		b.halfOpen[key] = true
+	}
+
+	return true
+}
+
+// scheduleCycle records the time of the next round of checks, and the interval between the rounds.
+func (b *BackendConfig) scheduleCycle(next time.Time, interval time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextCycle = next
+	b.cycleInterval = interval
+}
+
+// NextCheck returns the time the given server is checked next, according to the interval and its jitter,
+// and to the backoff and the Retry-After delaying the checks of the server. It is zero until the checks are scheduled.
+func (b *BackendConfig) NextCheck(server *url.URL) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.nextCycle.IsZero() {
+		return time.Time{}
+	}
+
+	return b.nextCycle.Add(time.Duration(b.skippedChecks[server.String()]+b.healthySkips[server.String()]) * b.cycleInterval)
+}
+
+// closeHalfOpen returns whether the check of the given server was a half-open probe, promoting a healthy server at once.
+func (b *BackendConfig) closeHalfOpen(u *url.URL) bool {
+	key := u.String()
+	if !b.halfOpen[key] {
+		return false
+	}
+
+	delete(b.halfOpen, key)
+	delete(b.consecutiveSuccesses, key)
+	return true
+}
+
+// degradation is the set of the probes reporting a server as degraded.
+type degradation uint8
+
+const (
+	degradedByLiveness degradation = 1 << iota
+	degradedByReadiness
+)
+
+// setDegraded records whether the given probe reports the given server as degraded,
+// and returns whether any probe does.
+// b.stateMu must be held.
+func (b *BackendConfig) setDegraded(u *url.URL, by degradation, degraded bool) bool {
+	key := u.String()
+	if !degraded {
+		remaining := b.degradations[key] &^ by
+		if remaining == 0 {
+			delete(b.degradations, key)
+			return false
+		}
+
+		b.degradations[key] = remaining
+		return true
+	}
+
+	if b.degradations == nil {
+		b.degradations = make(map[string]degradation)
+	}
+	b.degradations[key] |= by
+
+	return true
+}
+
+// degrade records the full weight of the given degraded server, and returns its degraded weight.
+func (b *BackendConfig) degrade(u *url.URL, fullWeight int) int {
+	if b.degradedWeights == nil {
+		b.degradedWeights = make(map[string]int)
+	}
+	b.degradedWeights[u.String()] = fullWeight
+
+	if b.DegradedWeight <= 0 {
+		return 1
+	}
+
+	return b.DegradedWeight
+}
+
+// updateDegradedWeight updates the weight of the given server in the load-balancer,
+// when it becomes degraded, or fully healthy again.
+// b.stateMu must be held.
+func (b *BackendConfig) updateDegradedWeight(ctx context.Context, u *url.URL, degraded bool) {
+	logger := log.FromContext(ctx)
+
+	fullWeight, wasDegraded := b.degradedWeights[u.String()]
+
+	switch {
+	case degraded && !wasDegraded:
+		weight := b.degrade(u, b.stopRampUp(u))
+
+		logger.Warnf("Health check degraded: reducing the weight of the server. Backend: %q URL: %q Weight: %d", b.name, u.String(), weight)
+		if err := b.LB.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
+			logger.Error(err)
+		}
+
+	case !degraded && wasDegraded:
+		delete(b.degradedWeights, u.String())
+
+		logger.Warnf("Health check up: restoring the weight of the server. Backend: %q URL: %q Weight: %d", b.name, u.String(), fullWeight)
+		if err := b.LB.UpsertServer(u, roundrobin.Weight(fullWeight)); err != nil {
+			logger.Error(err)
+		}
+	}
+}
+
+// recordCheck reports the result of the health check of the given server to the load-balancer,
+// if it keeps track of them.
+func recordCheck(backend *BackendConfig, u *url.URL, err error) {
+	recorder, ok := backend.LB.(CheckRecorder)
+	if !ok {
+		return
+	}
+
+	check := runtime.ServerCheck{CheckedAt: backend.getClock().Now()}
+	check.Address, check.Host = backend.httpTarget(u)
+	if err != nil {
+		check.Error = err.Error()
+		check.Reason = string(classifyFailure(err))
+
+		var statusErr *statusCodeError
+		if errors.As(err, &statusErr) {
+			check.StatusCode = statusErr.statusCode
+		}
+	}
+
+	recorder.RecordCheck(u, check)
+}
+
+// DisabledCount returns the number of servers of the backend which are currently disabled by the health check.
+func (b *BackendConfig) DisabledCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.disabledURLs)
+}
+
+// CheckNow checks the health of all the servers of the backend once, without updating the load-balancer,
+// and returns whether at least one of them is healthy. When none is, the returned error describes their failures.
+func (b *BackendConfig) CheckNow(ctx context.Context) (bool, error) {
+	b.mu.Lock()
+	disabledURLs := b.disabledURLs
+	b.mu.Unlock()
+
+	servers := withoutDisabledURLs(b.LB.Servers(), disabledURLs)
+	for _, disabledURL := range disabledURLs {
+		servers = append(servers, disabledURL.url)
+	}
+
+	if len(servers) == 0 {
+		return false, errors.New("no server to check")
+	}
+
+	var failures []string
+	for _, server := range servers {
+		err := checkHealth(ctx, server, b)
+		if err == nil || errors.Is(err, errDegraded) {
+			return true, nil
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", server.String(), err))
+	}
+
+	return false, fmt.Errorf("health check failed: %s", strings.Join(failures, "; "))
+}
+
+// publish sends the status change of the given server on the EventChan, if any, without blocking.
+func (b *BackendConfig) publish(u *url.URL, oldStatus, newStatus, reason string) {
+	if b.EventChan == nil {
+		return
+	}
+
+	event := StatusEvent{
+		Backend:   b.name,
+		Server:    u,
+		OldStatus: oldStatus,
+		NewStatus: newStatus,
+		Time:      b.getClock().Now(),
+		Reason:    reason,
+	}
+
+	select {
+	case b.EventChan <- event:
+	default:
+		log.WithoutContext().WithField(log.ServiceName, b.name).
+			Debugf("Dropping the health check event of the server %s, the channel is not ready", u.String())
+	}
+}
+
+// keepReason returns why a server failing its checks at the given time is kept in the load-balancer, empty if it is not.
+// b.stateMu must be held.
+func (b *BackendConfig) keepReason(now time.Time) string {
+	switch {
+	case now.Before(b.graceUntil):
+		return "during the startup grace period"
+	case b.KeepLastHealthy && !b.ShadowMode && len(b.LB.Servers()) == 1:
+		return "as the last server"
+	case b.MinHealthyServers > 0 && !b.ShadowMode && len(b.LB.Servers()) <= b.MinHealthyServers:
+		return fmt.Sprintf("to keep %d servers", b.MinHealthyServers)
+	default:
+		return ""
+	}
+}
+
+// failServer disables the given server failing its checks for the given reason, and removes it from the load-balancer, except in shadow mode.
+// It returns the full weight the server returns to the load-balancer with, and false if the server was already disabled.
+// b.stateMu must be held.
+func (b *BackendConfig) failServer(ctx context.Context, u *url.URL, now time.Time, reason string) (int, bool) {
+	weight := b.stopRampUp(u)
+	if fullWeight, ok := b.degradedWeights[u.String()]; ok {
+		// The server returns to the load-balancer with its full weight.
+		weight = fullWeight
+		delete(b.degradedWeights, u.String())
+	}
+
+	if !b.disable(u, weight) {
+		return weight, false
+	}
+	b.detectFlap(ctx, u, true, now)
+
+	if b.ShadowMode {
+		return weight, true
+	}
+
+	if err := b.removeServer(u); err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+	b.publish(u, serverUp, serverDown, reason)
+	b.updateRegistry(ctx, u, false)
+
+	return weight, true
+}
+
+// removeServer removes the given server from the load-balancer,
+// letting it drain its in-flight requests when a DrainDuration is configured.
+func (b *BackendConfig) removeServer(u *url.URL) error {
+	if drainer, ok := b.LB.(Drainer); ok && b.DrainDuration > 0 {
+		return drainer.DrainServer(u, b.DrainDuration)
+	}
+
+	return b.LB.RemoveServer(u)
+}
+
+// disable tracks the given server as disabled, and returns false if it already is.
+func (b *BackendConfig) disable(u *url.URL, weight int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.isDisabled(u) {
+		return false
+	}
+
+	b.disabledURLs = append(b.disabledURLs, backendURL{u, weight})
+	// The disabled servers are checked on each round of checks, with the UnhealthyInterval.
+	delete(b.healthySkips, u.String())
+	return true
+}
+
+// isDisabled returns whether the given server is disabled, b.mu must be held.
+func (b *BackendConfig) isDisabled(u *url.URL) bool {
+	for _, disabledURL := range b.disabledURLs {
+		if disabledURL.url.String() == u.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serverWeight returns the weight of the given server in the given load-balancer, defaulting to 1.
+func serverWeight(lb Balancer, u *url.URL) int {
+	wb, ok := lb.(weightedBalancer)
+	if !ok {
+		return 1
+	}
+
+	weight, ok := wb.ServerWeight(u)
+	if !ok {
+		return 1
+	}
+
+	return weight
+}
+
+// weightedBalancer is implemented by the Balancer knowing the weight of their servers, e.g. the oxy round-robin.
+type weightedBalancer interface {
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// withoutDisabledURLs returns the given server URLs which are not part of the disabled ones.
+func withoutDisabledURLs(urls []*url.URL, disabledURLs []backendURL) []*url.URL {
+	if len(disabledURLs) == 0 {
+		return urls
+	}
+
+	disabled := make(map[string]struct{}, len(disabledURLs))
+	for _, disabledURL := range disabledURLs {
+		disabled[disabledURL.url.String()] = struct{}{}
+	}
+
+	var enabledURLs []*url.URL
+	for _, u := range urls {
+		if _, ok := disabled[u.String()]; !ok {
+			enabledURLs = append(enabledURLs, u)
+		}
+	}
+
+	return enabledURLs
+}
+
+// GetHealthCheck returns the health check which is guaranteed to be a singleton.
+func GetHealthCheck(registry metrics.Registry) *HealthCheck {
+	once.Do(func() {
+		singleton = newHealthCheck(registry)
+	})
+	return singleton
+}
+
+func newHealthCheck(registry metrics.Registry) *HealthCheck {
+	return &HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics: metricsHealthcheck{
+			serverUpGauge:        registry.ServiceServerUpGauge(),
+			serverFailuresGauge:  registry.ServiceServerFailuresGauge(),
+			healthyRatioGauge:    registry.ServiceHealthyRatioGauge(),
+			disabledServersGauge: registry.ServiceDisabledServersGauge(),
+			certLifetimeGauge:    registry.ServiceServerCertLifetimeGauge(),
+			checkRequests:        registry.ServiceHealthCheckRequestsCounter(),
+			checkDuration:        registry.ServiceHealthCheckDurationHistogram(),
+			checkCycleDuration:   registry.ServiceHealthCheckCycleDurationHistogram(),
+		},
+	}
+}
+
+// NewBackendConfig Instantiate a new BackendConfig.
+func NewBackendConfig(options Options, backendName string) (*BackendConfig, error) {
+	expectedStatus, err := parseExpectedStatus(options.ExpectedStatus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected status %q: %w", options.ExpectedStatus, err)
+	}
+
+	var expectedBodyRegex *regexp.Regexp
+	if options.ExpectedBodyRegex != "" {
+		expectedBodyRegex, err = regexp.Compile(options.ExpectedBodyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected body regex %q: %w", options.ExpectedBodyRegex, err)
+		}
+	}
+
+	for _, path := range append([]string{options.Path}, options.Paths...) {
+		if err := validatePathTemplate(path); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+	}
+
+	for serverURL, serverOptions := range options.ServerOptions {
+		if err := validatePathTemplate(serverOptions.Path); err != nil {
+			return nil, fmt.Errorf("invalid path %q of the server %s: %w", serverOptions.Path, serverURL, err)
+		}
+	}
+
+	for _, port := range options.Ports {
+		if port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %d", port)
+		}
+	}
+
+	if options.Scheme == AutoScheme && options.Mode != "" && options.Mode != HTTPMode {
+		return nil, fmt.Errorf("the %q scheme is only supported by the HTTP checks", AutoScheme)
+	}
+
+	if options.Mode == WSMode && options.HTTP2 {
+		return nil, errors.New("the WebSocket checks do not support HTTP/2")
+	}
+
+	if err := validatePathTemplate(options.GRPCDialTarget); err != nil {
+		return nil, fmt.Errorf("invalid gRPC dial target %q: %w", options.GRPCDialTarget, err)
+	}
+
+	if options.SampleFraction < 0 || options.SampleFraction > 1 {
+		return nil, fmt.Errorf("invalid sample fraction %v, expected a fraction between 0 and 1", options.SampleFraction)
+	}
+
+	if options.LocalAddr != "" {
+		if net.ParseIP(options.LocalAddr) == nil {
+			return nil, fmt.Errorf("invalid local address %q: not an IP address", options.LocalAddr)
+		}
+		if options.UnixSocket != "" {
+			return nil, errors.New("the local address and the Unix socket are mutually exclusive")
+		}
+	}
+
+	if options.SidecarAddress != "" {
+		if options.Mode != "" && options.Mode != HTTPMode {
+			return nil, errors.New("the sidecar address is only supported by the HTTP checks")
+		}
+		if options.UnixSocket != "" {
+			return nil, errors.New("the sidecar address and the Unix socket are mutually exclusive")
+		}
+		if err := validatePathTemplate(options.SidecarAddress); err != nil {
+			return nil, fmt.Errorf("invalid sidecar address %q: %w", options.SidecarAddress, err)
+		}
+	}
+
+	switch options.GRPCTreatUnknownAs {
+	case "", GRPCUnknownDown, GRPCUnknownUp:
+	default:
+		return nil, fmt.Errorf("invalid interpretation %q of the unknown gRPC services, expected %q or %q", options.GRPCTreatUnknownAs, GRPCUnknownDown, GRPCUnknownUp)
+	}
+
+	if options.ServerName != "" {
+		options.TLSConfig, err = withServerName(options.Transport, options.TLSConfig, options.ServerName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure the server name %q: %w", options.ServerName, err)
+		}
+	}
+
+	var proxyURL *url.URL
+	if options.ProxyURL != "" {
+		proxyURL, err = parseProxyURL(options.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", options.ProxyURL, err)
+		}
+	}
+
+	if options.TLSConfig != nil || options.UnixSocket != "" || options.DialTimeout > 0 || options.LocalAddr != "" || options.ReResolve || proxyURL != nil {
+		options.Transport, err = newTransport(options, proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure the transport: %w", err)
+		}
+	}
+
+	if options.HTTP2 {
+		options.Transport, err = newHTTP2RoundTripper(options.Transport)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure HTTP/2: %w", err)
+		}
+	}
+
+	if options.Registry == nil {
+		options.Registry = nopRegistry{}
+	}
+
+	backend := &BackendConfig{
+		Options:           options,
+		name:              backendName,
+		expectedStatus:    expectedStatus,
+		expectedBodyRegex: expectedBodyRegex,
+		checked:           make(chan struct{}),
+		rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	if options.Mode == GRPCMode && options.GRPCUseWatch {
+		backend.grpcWatcher = newGRPCWatcher()
+	}
+
+	if options.Readiness != nil {
+		readinessOptions := *options.Readiness
+		readinessOptions.Readiness = nil
+		readinessOptions.StartUnhealthy = false
+		readinessOptions.LB = nil
+		if readinessOptions.Interval <= 0 {
+			readinessOptions.Interval = options.Interval
+		}
+
+		backend.readiness, err = NewBackendConfig(readinessOptions, backendName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readiness probe: %w", err)
+		}
+	}
+
+	if options.StartUnhealthy && options.LB != nil {
+		if err := backend.disableAll(); err != nil {
+			return nil, fmt.Errorf("unable to start the servers as down: %w", err)
+		}
+		backend.partiallyHealthy = backend.DisabledCount() > 0
+	}
+
+	return backend, nil
+}
+
+// disableAll removes all the servers from the load-balancer, until a check succeeds.
+// In shadow mode, the servers are only tracked as disabled.
+func (b *BackendConfig) disableAll() error {
+	for _, u := range b.LB.Servers() {
+		b.disable(u, serverWeight(b.LB, u))
+
+		if b.ShadowMode {
+			continue
+		}
+
+		if err := b.LB.RemoveServer(u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withServerName returns a copy of the given TLS configuration, or of the one of the given transport,
+// presenting the given server name.
+func withServerName(rt http.RoundTripper, tlsConfig *tls.Config, serverName string) (*tls.Config, error) {
+	if tlsConfig == nil {
+		transport, err := baseTransport(rt)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig = &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig
+		}
+	}
+
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = serverName
+
+	return tlsConfig, nil
+}
+
+// TransportCloner is implemented by the round-trippers wrapping an HTTP transport, e.g. the one of a ServersTransport,
+// so that the options of the checks overriding the transport keep its configuration, like its root CAs and client certificates.
+type TransportCloner interface {
+	// CloneTransport returns a copy of the wrapped transport.
+	CloneTransport() *http.Transport
+}
+
+// baseTransport returns a copy of the given round-tripper, or of the default transport when nil,
+// which the options of the checks override.
+// It fails for the round-trippers which are neither an HTTP transport nor a TransportCloner, whose configuration would be lost.
+func baseTransport(rt http.RoundTripper) (*http.Transport, error) {
+	switch transport := rt.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return transport.Clone(), nil
+	case TransportCloner:
+		return transport.CloneTransport(), nil
+	default:
+		return nil, fmt.Errorf("the round-tripper %T cannot be overridden, it is neither an *http.Transport nor a TransportCloner", rt)
+	}
+}
+
+// newTransport returns a copy of the transport of the given options, or of the default one,
+// configured with their TLS configuration, Unix domain socket, dial timeout, local address, resolution, and the given proxy.
+func newTransport(options Options, proxyURL *url.URL) (*http.Transport, error) {
+	transport, err := baseTransport(options.Transport)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TLSConfig != nil {
+		transport.TLSClientConfig = options.TLSConfig
+	}
+
+	dialer := &net.Dialer{Timeout: options.DialTimeout, LocalAddr: localTCPAddr(options.LocalAddr)}
+	if options.DialTimeout > 0 {
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = options.DialTimeout
+	}
+
+	if options.LocalAddr != "" {
+		transport.DialContext = dialer.DialContext
+	}
+
+	if options.ReResolve {
+		// Each check dials a new connection, resolving the hostname again.
+		dialer.Resolver = options.Resolver
+		transport.DialContext = dialer.DialContext
+		transport.DisableKeepAlives = true
+	}
+
+	if options.UnixSocket != "" {
+		// The socket is dialed whatever the address of the request.
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", options.UnixSocket)
+		}
+	}
+
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
+
+// localTCPAddr returns the TCP address the connections originating from the given local IP address are bound to,
+// nil when there is none.
+func localTCPAddr(localAddr string) net.Addr {
+	if localAddr == "" {
+		return nil
+	}
+
+	return &net.TCPAddr{IP: net.ParseIP(localAddr)}
+}
+
+// parseProxyURL parses the URL of a proxy, which uses either the http, https, or socks5 scheme.
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("missing host")
+	}
+
+	return u, nil
+}
+
+// http2RoundTripper sends the requests over HTTP/2, negotiated over TLS for the https scheme, and with h2c otherwise.
+type http2RoundTripper struct {
+	tls http.RoundTripper
+	h2c *http2.Transport
+}
+
+// newHTTP2RoundTripper returns an http2RoundTripper based on a copy of the given transport, or of the default one.
+func newHTTP2RoundTripper(rt http.RoundTripper) (*http2RoundTripper, error) {
+	transport, err := baseTransport(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := http2.ConfigureTransports(transport); err != nil {
+		return nil, err
+	}
+
+	dialContext := transport.DialContext
+	if dialContext == nil {
+		var dialer net.Dialer
+		dialContext = dialer.DialContext
+	}
+
+	return &http2RoundTripper{
+		tls: transport,
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialContext(ctx, network, addr)
+			},
+		},
+	}, nil
+}
+
+func (r *http2RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		return r.tls.RoundTrip(req)
+	}
+
+	req.URL.Scheme = "http"
+	return r.h2c.RoundTrip(req)
+}
+
+// parseExpectedStatus parses a comma-separated list of status codes and status code ranges.
+func parseExpectedStatus(value string) (types.HTTPCodeRanges, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var blocks []string
+	for _, block := range strings.Split(value, ",") {
+		block = strings.TrimSpace(block)
+
+		codes := strings.Split(block, "-")
+		if len(codes) > 2 {
+			return nil, fmt.Errorf("malformed status code range %q", block)
+		}
+
+		var bounds []int
+		for _, code := range codes {
+			statusCode, err := strconv.Atoi(strings.TrimSpace(code))
+			if err != nil {
+				return nil, fmt.Errorf("malformed status code %q in %q", code, block)
+			}
+
+			if statusCode < 100 || statusCode > 599 {
+				return nil, fmt.Errorf("status code %d out of range", statusCode)
+			}
+
+			bounds = append(bounds, statusCode)
+		}
+
+		if len(bounds) == 2 && bounds[0] > bounds[1] {
+			return nil, fmt.Errorf("malformed status code range %q: lower bound greater than upper bound", block)
+		}
+
+		blocks = append(blocks, strings.ReplaceAll(block, " ", ""))
+	}
+
+	return types.NewHTTPCodeRanges(blocks)
+}
+
+// checkHealth checks the health of the given server, sharing the result with
+// the other backends probing the same target during the same interval.
+// The servers followed through a gRPC Watch stream are not probed, the last status they pushed being their health.
+func (hc *HealthCheck) checkHealth(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	if backend.grpcWatcher != nil {
+		if watched, err := backend.grpcWatcher.result(serverURL); watched {
+			return err
+		}
+	}
+
+	key, ok := probeKey(serverURL, backend)
+	if !ok {
+		return hc.probe(ctx, serverURL, backend)
+	}
+
+	return hc.probes.do(key, backend, hc.getClock().Now(), func() error {
+		return hc.probe(ctx, serverURL, backend)
+	})
+}
+
+// probe checks the health of the given server, and records the duration of the check.
+// The probe is skipped when no probe slot frees up within the interval of the backend, or before the given context is done.
+func (hc *HealthCheck) probe(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	release, ok := hc.acquireSlot(ctx, backend)
+	if !ok {
+		return errProbeSkipped
+	}
+	defer release()
+
+	clock := hc.getClock()
+	start := clock.Now()
+	err := checkHealth(ctx, serverURL, backend)
+	hc.setCertLifetime(backend, serverURL)
+
+	if hc.metrics.checkDuration != nil {
+		// The duration is not bounded by the timeout, which applies to each request of the check, not to the whole check,
+		// e.g. when several paths or ports are checked.
+		hc.metrics.checkDuration.With("service", backend.name).Observe(clock.Now().Sub(start).Seconds())
+	}
+
+	if hc.metrics.checkRequests != nil {
+		// A degraded server passes its check.
+		result, reason := "success", ""
+		if err != nil && !errors.Is(err, errDegraded) {
+			result, reason = "failure", string(classifyFailure(err))
+		}
+
+		hc.metrics.checkRequests.With("service", backend.name, "url", serverURL.String(), "result", result, "reason", reason).Add(1)
+	}
+
+	return err
+}
+
+// acquireSlot waits up to the interval of the backend, and at the latest until the given context is done, for a free probe slot,
+// and returns the function releasing it.
+func (hc *HealthCheck) acquireSlot(ctx context.Context, backend *BackendConfig) (func(), bool) {
+	hc.slotsMu.Lock()
+	slots := hc.slots
+	hc.slotsMu.Unlock()
+
+	if slots == nil {
+		return func() {}, true
+	}
+
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	default:
+	}
+
+	timer := hc.getClock().NewTimer(backend.Interval)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	case <-timer.C():
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// probeKey returns the key identifying the target of a probe,
+// i.e. the resolved address and path, along with what is sent to it, and how.
+// Every option changing the outcome of a probe is either part of the key, or prevents the probe from being shared,
+// as checked by TestProbeKey_options.
+func probeKey(serverURL *url.URL, backend *BackendConfig) (string, bool) {
+	// The outcome of a probe depends on its TLS configuration, its credentials, its resolver, its evaluation,
+	// and the ports, certificate lifetimes, schemes, and cookies recorded by it, which are specific to the backend.
+	if backend.TLSConfig != nil || backend.AuthToken != "" || backend.AuthTokenFunc != nil || backend.Resolver != nil ||
+		backend.BasicAuth != nil || backend.BasicAuthFile != "" || backend.Evaluate != nil || backend.PortFromHeader != "" ||
+		backend.SignRequest != nil || backend.MinCertLifetime > 0 || backend.Scheme == AutoScheme || backend.CookieJar != nil ||
+		len(backend.GRPCDialOptions) > 0 {
+		return "", false
+	}
+
+	req, err := backend.newRequest(serverURL)
+	if err != nil {
+		return "", false
+	}
+
+	req, err = backend.setRequestOptions(req)
+	if err != nil {
+		return "", false
+	}
+
+	// The outcome of a probe also depends on what is expected from the response.
+	// The Body is part of the key whatever the method, as it is also the payload of the UDP checks.
+	return strings.Join([]string{
+		backend.Mode, backend.UnixSocket, backend.ProxyURL, req.Method, req.Host, req.URL.String(), strings.Join(backend.Paths, ","), backend.Body,
+		backend.ExpectedStatus, backend.ExpectedBody, backend.ExpectedBodyRegex, strconv.FormatBool(backend.HTTP2), fmt.Sprint(backend.Ports),
+		strconv.FormatBool(backend.HeadFallbackGet), backend.ExpectedALPN, backend.SlowThreshold.String(), fmt.Sprint(backend.ExpectedHeaders),
+		backend.LocalAddr, backend.GRPCDialTarget, backend.MaxClockSkew.String(), strconv.FormatBool(backend.MaxClockSkewDown),
+		strconv.FormatBool(backend.ReResolve), backend.MaxProbeDuration.String(), backend.SendString, backend.ExpectString,
+		strconv.FormatBool(backend.TreatResetAsHealthy), strconv.FormatBool(backend.ResetDegraded), fmt.Sprint(backend.ExpectedJSON),
+		backend.GRPCServiceName, fmt.Sprint(backend.GRPCMetadata), backend.GRPCTreatUnknownAs,
+		backend.DegradedHeader, backend.DegradedHeaderValue, strconv.Itoa(backend.DegradedStatus), strconv.Itoa(backend.DegradedWeight),
+		fmt.Sprint(req.Header), backend.Timeout.String(), backend.DialTimeout.String(), strconv.FormatBool(backend.FollowRedirects),
+		strconv.Itoa(backend.MaxRedirects), strconv.FormatBool(backend.SameHostRedirectsOnly), strconv.FormatBool(backend.MinCertLifetimeDown),
+		identity(backend.Transport), identity(backend.HTTPClient),
+	}, " "), true
+}
+
+// identity returns a key identifying the given value, by its address for the pointers, e.g. the round-tripper of the checks.
+func identity(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		return fmt.Sprintf("%T@%x", value, v.Pointer())
+	}
+
+	return fmt.Sprintf("%T%#v", value, value)
+}
+
+// probeRegistry deduplicates the probes of a given target across backends,
+// so that a server referenced by several services is probed once per interval.
+type probeRegistry struct {
+	mu      sync.Mutex
+	results map[string]*probeResult
+}
+
+type probeResult struct {
+	// done is closed once the probe is over.
+	done      chan struct{}
+	owner     string
+	startedAt time.Time
+	err       error
+}
+
+// reset forgets all the results, e.g. when the backends are reconfigured.
+func (r *probeRegistry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = nil
+}
+
+// do runs probe, unless a probe for the same key is in flight or recent enough,
+// in which case its result is returned instead.
+func (r *probeRegistry) do(key string, backend *BackendConfig, now time.Time, probe func() error) error {
+	r.mu.Lock()
+
+	if r.results == nil {
+		r.results = make(map[string]*probeResult)
+	}
+
+	if res, ok := r.results[key]; ok {
+		select {
+		case <-res.done:
+			// The backend which performed the probe is the one in charge of refreshing it,
+			// the others reuse its result as long as it is not older than their TTL.
+			maxAge := backend.tickInterval()
+			if backend.ProbeCacheTTL > 0 {
+				maxAge = backend.ProbeCacheTTL
+			}
+			if res.owner == backend.name {
+				// The owner refreshes the result on the check preceding its expiry, whatever the interval jitter.
+				maxAge -= backend.tickInterval() / 2
+			}
+
+			if now.Sub(res.startedAt) < maxAge {
+				r.mu.Unlock()
+				return res.err
+			}
+		default:
+			r.mu.Unlock()
+			<-res.done
+			return res.err
+		}
+	}
+
+	res := &probeResult{
+		done:      make(chan struct{}),
+		owner:     backend.name,
+		startedAt: now,
+	}
+	r.results[key] = res
+	r.mu.Unlock()
+
+	res.err = probe()
+	close(res.done)
+
+	return res.err
+}
+
+// checkHealth calls the proper health check function depending on the
+// backend config mode, defaults to HTTP, aborting the check once the given context is done.
+func checkHealth(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	if len(backend.Ports) == 0 || backend.Mode == DNSMode {
+		return backend.checkReset(checkHealthMode(ctx, serverURL, backend))
+	}
+
+	// The server is only healthy if all its ports are, and degraded if any of them is.
+	var degradedErr error
+	for _, port := range backend.Ports {
+		err := backend.checkReset(checkHealthMode(context.WithValue(ctx, portContextKey{}, port), serverURL, backend))
+		switch {
+		case err == nil:
+		case errors.Is(err, errDegraded):
+			degradedErr = err
+		default:
+			return fmt.Errorf("port %d: %w", port, err)
+		}
+	}
+
+	return degradedErr
+}
+
+// checkReset returns the outcome of a check failing with the given error, given the TreatResetAsHealthy:
+// a server resetting the connection of the check is healthy, or degraded with ResetDegraded.
+func (b *BackendConfig) checkReset(err error) error {
+	if err == nil || !b.TreatResetAsHealthy || !errors.Is(err, syscall.ECONNRESET) {
+		return err
+	}
+
+	if b.ResetDegraded {
+		return fmt.Errorf("%w: connection reset by the server: %v", errDegraded, err)
+	}
+
+	return nil
+}
+
+// portContextKey is the context key of the port checked among the Ports.
+type portContextKey struct{}
+
+// checkedPort returns the port checked on the given server, zero for the port of the server URL,
+// the context of the check of one of the Ports carrying it.
+func (b *BackendConfig) checkedPort(ctx context.Context, serverURL *url.URL) int {
+	if port, ok := ctx.Value(portContextKey{}).(int); ok {
+		return port
+	}
+	return b.port(serverURL)
+}
+
+// checkHealthMode checks the given server according to the check mode of the backend.
+func checkHealthMode(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	switch backend.Options.Mode {
+	case GRPCMode:
+		return checkHealthGRPC(ctx, serverURL, backend)
+	case TCPMode:
+		return checkHealthTCP(ctx, serverURL, backend)
+	case UDPMode:
+		return checkHealthUDP(ctx, serverURL, backend)
+	case DNSMode:
+		return checkHealthDNS(ctx, serverURL, backend)
+	case WSMode:
+		return checkHealthWebSocket(ctx, serverURL, backend)
+	default:
+		err := checkHealthHTTP(ctx, serverURL, backend)
+		if err != nil && !errors.Is(err, errDegraded) {
+			// With the AutoScheme, the scheme of the server is resolved again on its next check.
+			backend.forgetScheme(serverURL)
+		}
+		return err
+	}
+}
+
+// checkHealthHTTP returns an error with a meaningful description if the health check failed.
+// Dedicated to HTTP servers.
+func checkHealthHTTP(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	reqs, err := backend.newRequests(serverURL)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	if port, ok := ctx.Value(portContextKey{}).(int); ok && backend.UnixSocket == "" && backend.SidecarAddress == "" {
+		for _, req := range reqs {
+			req.URL.Host = net.JoinHostPort(req.URL.Hostname(), strconv.Itoa(port))
+		}
+	}
+
+	if backend.MaxProbeDuration <= 0 {
+		return checkRequestsHTTP(ctx, serverURL, reqs, backend)
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, backend.MaxProbeDuration)
+	defer cancel()
+
+	err = checkRequestsHTTP(probeCtx, serverURL, reqs, backend)
+	if err != nil && ctx.Err() == nil && errors.Is(probeCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("health check aborted after the max probe duration of %s: %w", backend.MaxProbeDuration, err)
+	}
+
+	return err
+}
+
+// checkRequestsHTTP returns an error if none of the given requests of an HTTP check, one per candidate path, succeeds.
+func checkRequestsHTTP(ctx context.Context, serverURL *url.URL, reqs []*http.Request, backend *BackendConfig) error {
+	if len(reqs) == 1 {
+		return checkRequestHTTP(ctx, serverURL, reqs[0], backend)
+	}
+
+	// The server is healthy as soon as one of the candidate paths is.
+	var failures []string
+	for _, req := range reqs {
+		err := checkRequestHTTP(ctx, serverURL, req, backend)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, errDegraded) {
+			return err
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", req.URL.Path, err))
+	}
+
+	return fmt.Errorf("all the health check paths failed: %s", strings.Join(failures, "; "))
+}
+
+// checkRequestHTTP sends the given HTTP check request of the server, and returns an error if the response is not healthy.
+func checkRequestHTTP(ctx context.Context, serverURL *url.URL, req *http.Request, backend *BackendConfig) error {
+	req, err := backend.setRequestOptions(req)
+	if err != nil {
+		return err
+	}
+
+	// The deadline covers the whole check, including the read of the body,
+	// and the connection is torn down as soon as the check is over.
+	if backend.Options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, backend.Options.Timeout)
+		defer cancel()
+	}
+	req = req.WithContext(ctx)
+
+	client := http.Client{Transport: backend.Options.Transport}
+	if backend.Options.HTTPClient != nil {
+		client = *backend.Options.HTTPClient
+	}
+
+	if backend.Options.CookieJar != nil {
+		client.Jar = backend.Options.CookieJar
+	}
+
+	client.CheckRedirect = backend.wrapCheckRedirect(client.CheckRedirect)
+
+	start := time.Now()
+	resp, err := backend.do(&client, serverURL, req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	if backend.HeadFallbackGet && req.Method == http.MethodHead &&
+		(resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		_ = resp.Body.Close()
+
+		resp, err = backend.fallbackGet(&client, serverURL, req)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer resp.Body.Close()
+
+	backend.recordHeaderPort(serverURL, resp)
+
+	if backend.ExpectedALPN != "" && resp.TLS != nil && resp.TLS.NegotiatedProtocol != backend.ExpectedALPN {
+		return fmt.Errorf("%w: negotiated %q, expected %q", errUnexpectedALPN, resp.TLS.NegotiatedProtocol, backend.ExpectedALPN)
+	}
+
+	err = backend.evaluateResponse(resp, time.Since(start))
+	if err != nil && !errors.Is(err, errDegraded) {
+		return err
+	}
+
+	if latency := time.Since(start); err == nil && backend.SlowThreshold > 0 && latency > backend.SlowThreshold {
+		err = fmt.Errorf("%w: responded in %s, beyond %s", errDegraded, latency.Truncate(time.Millisecond), backend.SlowThreshold)
+	}
+
+	// A server down for one reason is down, whatever the other reasons it is degraded for.
+	now := time.Now()
+	for _, checkErr := range []error{backend.checkCertLifetime(serverURL, resp, now), backend.checkClockSkew(resp, now)} {
+		if checkErr == nil {
+			continue
+		}
+		if !errors.Is(checkErr, errDegraded) {
+			return checkErr
+		}
+		err = checkErr
+	}
+
+	return err
+}
+
+// fallbackGet sends the given HEAD check request again with the GET method, signed again if needed.
+func (b *BackendConfig) fallbackGet(client *http.Client, serverURL *url.URL, req *http.Request) (*http.Response, error) {
+	getReq := req.Clone(req.Context())
+	getReq.Method = http.MethodGet
+
+	if b.Options.SignRequest != nil {
+		if err := b.Options.SignRequest(getReq); err != nil {
+			return nil, fmt.Errorf("%w: failed to sign the request: %v", errConfiguration, err)
+		}
+	}
+
+	resp, err := b.do(client, serverURL, getReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// evaluateResponse evaluates the response of an HTTP check, received after the given latency,
+// with the Evaluate function, or the built-in evaluation when there is none.
+func (b *BackendConfig) evaluateResponse(resp *http.Response, latency time.Duration) error {
+	if b.Evaluate == nil {
+		return b.evaluate(resp)
+	}
+
+	healthy, err := b.Evaluate(resp, latency)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate the response: %w", err)
+	}
+	if !healthy {
+		return errors.New("response evaluated as unhealthy")
+	}
+	return nil
+}
+
+// evaluate is the built-in evaluation of the response of an HTTP check,
+// returning an error if its status code or its body is not the expected one, or errDegraded for a degraded server.
+func (b *BackendConfig) evaluate(resp *http.Response) error {
+	degraded := b.isDegraded(resp)
+
+	switch {
+	case degraded:
+		// The status code of a degraded server is not checked, it may be the DegradedStatus.
+
+	case b.expectedStatus != nil:
+		if !b.expectedStatus.Contains(resp.StatusCode) {
+			return b.newStatusCodeError("received unexpected status code", resp)
+		}
+
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest:
+		return b.newStatusCodeError("received error status code", resp)
+	}
+
+	if err := checkHeaders(resp.Header, b); err != nil {
+		return err
+	}
+
+	if err := checkBody(resp.Body, b); err != nil {
+		return err
+	}
+
+	if degraded {
+		return errDegraded
+	}
+
+	return nil
+}
+
+// isDegraded returns whether the given response of an HTTP check reports a degraded server.
+func (b *BackendConfig) isDegraded(resp *http.Response) bool {
+	if b.DegradedStatus != 0 && resp.StatusCode == b.DegradedStatus {
+		return true
+	}
+
+	if b.DegradedHeader == "" {
+		return false
+	}
+
+	values := resp.Header.Values(b.DegradedHeader)
+	if b.DegradedHeaderValue == "" {
+		return len(values) > 0
+	}
+
+	for _, value := range values {
+		if value == b.DegradedHeaderValue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statusCodeError is returned by the HTTP health checks receiving a response with a failing status code.
+type statusCodeError struct {
+	msg        string
+	statusCode int
+	// retryAfter is the delay requested by the Retry-After header of a 503 response, if any.
+	retryAfter time.Duration
+}
+
+// newStatusCodeError returns the error of a response with a failing status code,
+// honoring its Retry-After header if it is a 503.
+func (b *BackendConfig) newStatusCodeError(msg string, resp *http.Response) *statusCodeError {
+	statusErr := &statusCodeError{msg: msg, statusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		statusErr.retryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), b.getClock().Now())
+	}
+	return statusErr
+}
+
+func (e *statusCodeError) Error() string {
+	return fmt.Sprintf("%s: %d", e.msg, e.statusCode)
+}
+
+// parseRetryAfter returns the delay requested by the given Retry-After header value,
+// in either the delta-seconds or the HTTP-date form.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
 	}
 
-	if b.Port != 0 {
-		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(b.Port))
+	delay := date.Sub(now)
+	if delay <= 0 {
+		return 0, false
 	}
 
-	return http.NewRequest(http.MethodGet, u.String(), http.NoBody)
+	return delay, true
 }
 
-// setRequestOptions sets all request options present on the BackendConfig.
-func (b *BackendConfig) setRequestOptions(req *http.Request) *http.Request {
-	if b.Options.Hostname != "" {
-		req.Host = b.Options.Hostname
+// checkHeaders returns an error if the given response headers do not hold the expected headers.
+func checkHeaders(header http.Header, backend *BackendConfig) error {
+	names := make([]string, 0, len(backend.ExpectedHeaders))
+	for name := range backend.ExpectedHeaders {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	for k, v := range b.Options.Headers {
-		req.Header.Set(k, v)
-	}
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			return fmt.Errorf("response header %q is missing", name)
+		}
 
-	if b.Options.Method != "" {
-		req.Method = strings.ToUpper(b.Options.Method)
+		expected := backend.ExpectedHeaders[name]
+		if expected == "" {
+			continue
+		}
+
+		var found bool
+		for _, value := range values {
+			if value == expected {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("response header %q is %q, expected %q", name, strings.Join(values, ","), expected)
+		}
 	}
 
-	return req
+	return nil
 }
 
-// HealthCheck struct.
-type HealthCheck struct {
-	Backends map[string]*BackendConfig
-	metrics  metricsHealthcheck
-	cancel   context.CancelFunc
-}
+// checkBody returns an error if the given response body does not match the expected body.
+// Only the first maxBodySize bytes of the body are matched.
+func checkBody(body io.Reader, backend *BackendConfig) error {
+	if backend.ExpectedBody == "" && backend.expectedBodyRegex == nil && len(backend.ExpectedJSON) == 0 {
+		return nil
+	}
 
-// SetBackendsConfiguration set backends configuration.
-func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backends map[string]*BackendConfig) {
-	hc.Backends = backends
-	if hc.cancel != nil {
-		hc.cancel()
+	content, err := io.ReadAll(io.LimitReader(body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
-	ctx, cancel := context.WithCancel(parentCtx)
-	hc.cancel = cancel
 
-	for _, backend := range backends {
-		currentBackend := backend
-		safe.Go(func() {
-			hc.execute(ctx, currentBackend)
-		})
+	if backend.ExpectedBody != "" && !bytes.Contains(content, []byte(backend.ExpectedBody)) {
+		return fmt.Errorf("response body does not contain %q", backend.ExpectedBody)
+	}
+
+	if backend.expectedBodyRegex != nil && !backend.expectedBodyRegex.Match(content) {
+		return fmt.Errorf("response body does not match %q", backend.ExpectedBodyRegex)
+	}
+
+	if len(backend.ExpectedJSON) > 0 {
+		return checkJSON(content, backend.ExpectedJSON)
 	}
+
+	return nil
 }
 
-func (hc *HealthCheck) execute(ctx context.Context, backend *BackendConfig) {
-	logger := log.FromContext(ctx)
+// wrapCheckRedirect returns the redirect policy of the HTTP checks, which applies the redirect options of the checks,
+// and then delegates to the given redirect policy, e.g. the one of the configured HTTPClient, if any.
+func (b *BackendConfig) wrapCheckRedirect(next func(req *http.Request, via []*http.Request) error) func(req *http.Request, via []*http.Request) error {
+	switch {
+	case !b.FollowRedirects:
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
 
-	logger.Debugf("Initial health check for backend: %q", backend.name)
-	hc.checkServersLB(ctx, backend)
+	case b.MaxRedirects > 0 || b.SameHostRedirectsOnly:
+		return func(req *http.Request, via []*http.Request) error {
+			if err := b.checkRedirect(req, via); err != nil {
+				return err
+			}
 
-	ticker := time.NewTicker(backend.Interval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Debugf("Stopping current health check goroutines of backend: %s", backend.name)
-			return
-		case <-ticker.C:
-			logger.Debugf("Routine health check refresh for backend: %s", backend.name)
-			hc.checkServersLB(ctx, backend)
+			if next == nil {
+				return nil
+			}
+			return next(req, via)
 		}
+
+	default:
+		return next
 	}
 }
 
-func (hc *HealthCheck) checkServersLB(ctx context.Context, backend *BackendConfig) {
-	logger := log.FromContext(ctx)
+// checkRedirect enforces the maximum number of redirects and the same host restriction of the HTTP checks.
+func (b *BackendConfig) checkRedirect(req *http.Request, via []*http.Request) error {
+	maxRedirects := b.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
 
-	enabledURLs := backend.LB.Servers()
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
 
-	var newDisabledURLs []backendURL
-	for _, disabledURL := range backend.disabledURLs {
-		serverUpMetricValue := float64(0)
+	if b.SameHostRedirectsOnly && req.URL.Host != via[0].URL.Host {
+		return fmt.Errorf("redirect to another host %s", req.URL.Host)
+	}
 
-		if err := checkHealth(disabledURL.url, backend); err == nil {
-			logger.Warnf("Health check up: returning to server list. Backend: %q URL: %q Weight: %d",
-				backend.name, disabledURL.url.String(), disabledURL.weight)
-			if err = backend.LB.UpsertServer(disabledURL.url, roundrobin.Weight(disabledURL.weight)); err != nil {
-				logger.Error(err)
-			}
-			serverUpMetricValue = 1
-		} else {
-			logger.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disabledURL.url.String(), err)
-			newDisabledURLs = append(newDisabledURLs, disabledURL)
-		}
+	return nil
+}
 
-		labelValues := []string{"service", backend.name, "url", disabledURL.url.String()}
-		hc.metrics.serverUpGauge.With(labelValues...).Set(serverUpMetricValue)
+// checkHealthGRPC returns an error with a meaningful description if the health check failed.
+// Dedicated to gRPC servers implementing gRPC Health Checking Protocol v1.
+func checkHealthGRPC(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	serverAddr, err := grpcServerAddr(ctx, serverURL, backend)
+	if err != nil {
+		return err
 	}
 
-	backend.disabledURLs = newDisabledURLs
+	ctx, cancel := context.WithTimeout(ctx, backend.Options.Timeout)
+	defer cancel()
 
-	for _, enabledURL := range enabledURLs {
-		serverUpMetricValue := float64(1)
+	conn, err := grpc.DialContext(ctx, serverAddr, grpcDialOptions(backend)...)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("fail to connect to %s within %s: %w", serverAddr, backend.Options.Timeout, err)
+		}
+		return fmt.Errorf("fail to connect to %s: %w", serverAddr, err)
+	}
+	defer func() { _ = conn.Close() }()
 
-		if err := checkHealth(enabledURL, backend); err != nil {
-			weight := 1
-			rr, ok := backend.LB.(*roundrobin.RoundRobin)
-			if ok {
-				var gotWeight bool
-				weight, gotWeight = rr.ServerWeight(enabledURL)
-				if !gotWeight {
-					weight = 1
-				}
-			}
+	if len(backend.Options.GRPCMetadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(backend.Options.GRPCMetadata))
+	}
 
-			logger.Warnf("Health check failed, removing from server list. Backend: %q URL: %q Weight: %d Reason: %s",
-				backend.name, enabledURL.String(), weight, err)
-			if err := backend.LB.RemoveServer(enabledURL); err != nil {
-				logger.Error(err)
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: backend.Options.GRPCServiceName})
+	if err != nil {
+		if stat, ok := status.FromError(err); ok {
+			switch stat.Code() {
+			case codes.Unimplemented:
+				return fmt.Errorf("gRPC server does not implement the health protocol: %w", err)
+			case codes.DeadlineExceeded:
+				return fmt.Errorf("gRPC health check timeout: %w", err)
+			case codes.NotFound:
+				return unknownGRPCService(ctx, serverAddr, backend, stat.Message())
 			}
-
-			backend.disabledURLs = append(backend.disabledURLs, backendURL{enabledURL, weight})
-			serverUpMetricValue = 0
 		}
 
-		labelValues := []string{"service", backend.name, "url", enabledURL.String()}
-		hc.metrics.serverUpGauge.With(labelValues...).Set(serverUpMetricValue)
+		return fmt.Errorf("gRPC health check failed: %w", err)
 	}
-}
 
-// GetHealthCheck returns the health check which is guaranteed to be a singleton.
-func GetHealthCheck(registry metrics.Registry) *HealthCheck {
-	once.Do(func() {
-		singleton = newHealthCheck(registry)
-	})
-	return singleton
+	return grpcServingStatus(ctx, serverAddr, backend, resp.Status)
 }
 
-func newHealthCheck(registry metrics.Registry) *HealthCheck {
-	return &HealthCheck{
-		Backends: make(map[string]*BackendConfig),
-		metrics: metricsHealthcheck{
-			serverUpGauge: registry.ServiceServerUpGauge(),
-		},
+// grpcServerAddr returns the address of the gRPC health service of the given server, or its GRPCDialTarget.
+func grpcServerAddr(ctx context.Context, serverURL *url.URL, backend *BackendConfig) (string, error) {
+	if backend.GRPCDialTarget != "" {
+		return expandAddress(backend.GRPCDialTarget, serverURL), nil
+	}
+
+	u, err := serverURL.Parse(backend.path(serverURL))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse server URL: %w", err)
+	}
+
+	port := u.Port()
+	if p := backend.checkedPort(ctx, serverURL); p != 0 {
+		port = strconv.Itoa(p)
 	}
+
+	return net.JoinHostPort(u.Hostname(), port), nil
 }
 
-// NewBackendConfig Instantiate a new BackendConfig.
-func NewBackendConfig(options Options, backendName string) *BackendConfig {
-	return &BackendConfig{
-		Options: options,
-		name:    backendName,
+// grpcDialOptions returns the options of the connections to the gRPC health services of the servers.
+func grpcDialOptions(backend *BackendConfig) []grpc.DialOption {
+	var opts []grpc.DialOption
+	switch {
+	case backend.Options.Scheme == "http", backend.Options.Scheme == "h2c":
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	case backend.Options.TLSConfig != nil:
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(backend.Options.TLSConfig)))
+	case backend.Options.Scheme == "":
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if backend.Options.LocalAddr != "" {
+		dialer := net.Dialer{LocalAddr: localTCPAddr(backend.Options.LocalAddr)}
+		opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}))
 	}
+
+	return append(opts, backend.Options.GRPCDialOptions...)
 }
 
-// checkHealth calls the proper health check function depending on the
-// backend config mode, defaults to HTTP.
-func checkHealth(serverURL *url.URL, backend *BackendConfig) error {
-	if backend.Options.Mode == GRPCMode {
-		return checkHealthGRPC(serverURL, backend)
+// grpcServingStatus returns the outcome of a gRPC check of a server reporting the given serving status.
+func grpcServingStatus(ctx context.Context, serverAddr string, backend *BackendConfig, stat healthpb.HealthCheckResponse_ServingStatus) error {
+	switch stat {
+	case healthpb.HealthCheckResponse_SERVING:
+		return nil
+	case healthpb.HealthCheckResponse_UNKNOWN, healthpb.HealthCheckResponse_SERVICE_UNKNOWN:
+		return unknownGRPCService(ctx, serverAddr, backend, stat.String())
+	default:
+		return fmt.Errorf("%w: %v", errNotServing, stat)
 	}
-	return checkHealthHTTP(serverURL, backend)
 }
 
-// checkHealthHTTP returns an error with a meaningful description if the health check failed.
-// Dedicated to HTTP servers.
-func checkHealthHTTP(serverURL *url.URL, backend *BackendConfig) error {
-	req, err := backend.newRequest(serverURL)
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+// unknownGRPCService returns the outcome of a gRPC check of a server not knowing the checked service,
+// according to the GRPCTreatUnknownAs option.
+func unknownGRPCService(ctx context.Context, serverAddr string, backend *BackendConfig, detail string) error {
+	if backend.GRPCTreatUnknownAs == GRPCUnknownUp {
+		log.FromContext(ctx).Warnf("gRPC server %s does not know the service %q (%s), considered up", serverAddr, backend.GRPCServiceName, detail)
+		return nil
 	}
 
-	req = backend.setRequestOptions(req)
+	return fmt.Errorf("%w %q: %s", errServiceUnknown, backend.GRPCServiceName, detail)
+}
 
-	client := http.Client{
-		Timeout:   backend.Options.Timeout,
-		Transport: backend.Options.Transport,
+// checkHealthTCP returns an error with a meaningful description if the health check failed.
+// Dedicated to servers only accepting raw TCP connections: a server is healthy if a connection can be established.
+func checkHealthTCP(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	port := serverURL.Port()
+	if p := backend.checkedPort(ctx, serverURL); p != 0 {
+		port = strconv.Itoa(p)
 	}
 
-	if !backend.FollowRedirects {
-		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
+	if port == "" {
+		switch serverURL.Scheme {
+		case "https":
+			port = "443"
+		default:
+			port = "80"
 		}
 	}
 
-	resp, err := client.Do(req)
+	serverAddr := net.JoinHostPort(serverURL.Hostname(), port)
+
+	dialer := net.Dialer{Timeout: backend.Options.Timeout, LocalAddr: localTCPAddr(backend.Options.LocalAddr)}
+	conn, err := dialer.DialContext(ctx, "tcp", serverAddr)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return fmt.Errorf("fail to connect to %s: %w", serverAddr, err)
 	}
 
-	defer resp.Body.Close()
+	if backend.SendString == "" && backend.ExpectString == "" {
+		return conn.Close()
+	}
+	defer func() { _ = conn.Close() }()
+
+	if backend.Options.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(backend.Options.Timeout)); err != nil {
+			return fmt.Errorf("fail to set the deadline of the connection to %s: %w", serverAddr, err)
+		}
+	}
+
+	if backend.SendString != "" {
+		if _, err := io.WriteString(conn, backend.SendString); err != nil {
+			return fmt.Errorf("fail to send to %s: %w", serverAddr, err)
+		}
+	}
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
-		return fmt.Errorf("received error status code: %v", resp.StatusCode)
+	if backend.ExpectString != "" {
+		return expectString(conn, serverAddr, backend.ExpectString)
 	}
 
 	return nil
 }
 
-// checkHealthGRPC returns an error with a meaningful description if the health check failed.
-// Dedicated to gRPC servers implementing gRPC Health Checking Protocol v1.
-func checkHealthGRPC(serverURL *url.URL, backend *BackendConfig) error {
-	u, err := serverURL.Parse(backend.Path)
-	if err != nil {
-		return fmt.Errorf("failed to parse server URL: %w", err)
-	}
+// expectString reads the given connection until its response contains the expected string,
+// and returns an error if it does not, once the connection is closed or its deadline exceeded.
+// Only the first maxBodySize bytes of the response are matched.
+func expectString(conn net.Conn, serverAddr, expected string) error {
+	var response []byte
+	buf := make([]byte, 512)
+	for len(response) < maxBodySize {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+
+		if bytes.Contains(response, []byte(expected)) {
+			return nil
+		}
 
-	port := u.Port()
-	if backend.Options.Port != 0 {
-		port = strconv.Itoa(backend.Options.Port)
+		if err != nil {
+			return fmt.Errorf("response of %s does not contain %q: %w", serverAddr, expected, err)
+		}
 	}
 
-	serverAddr := net.JoinHostPort(u.Hostname(), port)
+	return fmt.Errorf("response of %s does not contain %q", serverAddr, expected)
+}
 
-	var opts []grpc.DialOption
-	switch backend.Options.Scheme {
-	case "http", "h2c", "":
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// checkHealthUDP returns an error if the server does not reply to a datagram within the timeout.
+// Dedicated to UDP servers. As UDP is connectionless, a reply only proves that the socket is accepting datagrams.
+func checkHealthUDP(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	port := serverURL.Port()
+	if p := backend.checkedPort(ctx, serverURL); p != 0 {
+		port = strconv.Itoa(p)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), backend.Options.Timeout)
-	defer cancel()
+	if port == "" {
+		return fmt.Errorf("missing port for the UDP server %s", serverURL.String())
+	}
 
-	conn, err := grpc.DialContext(ctx, serverAddr, opts...)
+	serverAddr := net.JoinHostPort(serverURL.Hostname(), port)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", serverAddr)
 	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			return fmt.Errorf("fail to connect to %s within %s: %w", serverAddr, backend.Options.Timeout, err)
-		}
 		return fmt.Errorf("fail to connect to %s: %w", serverAddr, err)
 	}
 	defer func() { _ = conn.Close() }()
 
-	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
-	if err != nil {
-		if stat, ok := status.FromError(err); ok {
-			switch stat.Code() {
-			case codes.Unimplemented:
-				return fmt.Errorf("gRPC server does not implement the health protocol: %w", err)
-			case codes.DeadlineExceeded:
-				return fmt.Errorf("gRPC health check timeout: %w", err)
-			}
-		}
+	deadline := time.Now().Add(backend.Options.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
 
-		return fmt.Errorf("gRPC health check failed: %w", err)
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write([]byte(backend.Options.Body)); err != nil {
+		return fmt.Errorf("fail to send a datagram to %s: %w", serverAddr, err)
+	}
+
+	// Any datagram is a reply, whatever its content.
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return fmt.Errorf("no reply from %s within %s: %w", serverAddr, backend.Options.Timeout, err)
+	}
+
+	return nil
+}
+
+// checkHealthDNS returns an error if the hostname of the server does not resolve to any address within the timeout.
+// Dedicated to the servers addressed by a DNS name, whose records come and go.
+func checkHealthDNS(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	resolver := backend.Options.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	if backend.Options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, backend.Options.Timeout)
+		defer cancel()
+	}
+
+	host := serverURL.Hostname()
+
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("fail to resolve %s: %w", host, err)
 	}
 
-	if resp.Status != healthpb.HealthCheckResponse_SERVING {
-		return fmt.Errorf("received gRPC status code: %v", resp.Status)
+	if len(addrs) == 0 {
+		return fmt.Errorf("no address for %s", host)
 	}
 
 	return nil
@@ -361,6 +3171,12 @@ type StatusUpdater interface {
 	RegisterStatusUpdater(fn func(up bool)) error
 }
 
+// CheckRecorder should be implemented by a Balancer keeping track of the result
+// of the last health check of each of its servers.
+type CheckRecorder interface {
+	RecordCheck(u *url.URL, check runtime.ServerCheck)
+}
+
 // NewLBStatusUpdater returns a new LbStatusUpdater.
 func NewLBStatusUpdater(bh BalancerHandler, info *runtime.ServiceInfo, hc *dynamic.ServerHealthCheck) *LbStatusUpdater {
 	return &LbStatusUpdater{
@@ -377,6 +3193,13 @@ type LbStatusUpdater struct {
 	serviceInfo      *runtime.ServiceInfo // can be nil
 	updaters         []func(up bool)
 	wantsHealthCheck bool
+	inFlight         *InFlightCounter // can be nil
+
+	// drainingMu guards draining, along with the updates of the server statuses.
+	drainingMu sync.Mutex
+	// draining holds the servers being drained, keyed by server URL,
+	// their channel being closed once they stop draining.
+	draining map[string]chan struct{}
 }
 
 // RegisterStatusUpdater adds fn to the list of hooks that are run when the
@@ -403,27 +3226,31 @@ func (lb *LbStatusUpdater) RemoveServer(u *url.URL) error {
 	if err != nil {
 		return err
 	}
-	if lb.serviceInfo != nil {
-		lb.serviceInfo.UpdateServerStatus(u.String(), serverDown)
-	}
+	lb.setServerStatus(u, serverDown)
 	log.FromContext(ctx).Debugf("child %s now %s", u.String(), serverDown)
 
+	lb.propagateRemoval(ctx, upBefore)
+	return nil
+}
+
+// propagateRemoval propagates the new down status of the Balancer,
+// if it was up before the removal of a server and it has no more servers.
+func (lb *LbStatusUpdater) propagateRemoval(ctx context.Context, upBefore bool) {
 	if !upBefore {
 		// we were already down, and we still are, no need to propagate.
 		log.FromContext(ctx).Debugf("Still %s, no need to propagate", serverDown)
-		return nil
+		return
 	}
 	if len(lb.BalancerHandler.Servers()) > 0 {
 		// we were up, and we still are, no need to propagate
 		log.FromContext(ctx).Debugf("Still %s, no need to propagate", serverUp)
-		return nil
+		return
 	}
 
 	log.FromContext(ctx).Debugf("Propagating new %s status", serverDown)
 	for _, fn := range lb.updaters {
 		fn(false)
 	}
-	return nil
 }
 
 // UpsertServer adds the given server to the BalancerHandler,
@@ -435,9 +3262,7 @@ func (lb *LbStatusUpdater) UpsertServer(u *url.URL, options ...roundrobin.Server
 	if err != nil {
 		return err
 	}
-	if lb.serviceInfo != nil {
-		lb.serviceInfo.UpdateServerStatus(u.String(), serverUp)
-	}
+	lb.setServerStatus(u, serverUp)
 	log.FromContext(ctx).Debugf("child %s now %s", u.String(), serverUp)
 
 	if upBefore {
@@ -453,9 +3278,35 @@ func (lb *LbStatusUpdater) UpsertServer(u *url.URL, options ...roundrobin.Server
 	return nil
 }
 
+// ServerWeight returns the weight of the given server in the BalancerHandler, if it knows it.
+func (lb *LbStatusUpdater) ServerWeight(u *url.URL) (int, bool) {
+	wb, ok := lb.BalancerHandler.(weightedBalancer)
+	if !ok {
+		return 0, false
+	}
+
+	return wb.ServerWeight(u)
+}
+
+// RecordCheck records the result of the last health check of the given server in the ServiceInfo.
+func (lb *LbStatusUpdater) RecordCheck(u *url.URL, check runtime.ServerCheck) {
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateServerCheck(u.String(), check)
+	}
+}
+
 // Balancers is a list of Balancers(s) that implements the Balancer interface.
+// Its elements are primaries, unless wrapped in a MirrorBalancer:
+// the primaries are always operated on before the mirrors,
+// and only the failures of the primaries are reported.
 type Balancers []Balancer
 
+// MirrorBalancer gives the mirror role to the wrapped Balancer within Balancers.
+// The failures to update a mirror are logged, but do not impact the primaries.
+type MirrorBalancer struct {
+	Balancer
+}
+
 // Servers returns the deduplicated server URLs from all the Balancer.
 // Note that the deduplication is only possible because all the underlying
 // balancers are of the same kind (the oxy implementation).
@@ -465,7 +3316,7 @@ func (b Balancers) Servers() []*url.URL {
 	seen := make(map[string]struct{})
 
 	var servers []*url.URL
-	for _, lb := range b {
+	for _, lb := range b.ordered() {
 		for _, server := range lb.Servers() {
 			key := serverKey(server)
 			if _, ok := seen[key]; ok {
@@ -483,25 +3334,87 @@ func (b Balancers) Servers() []*url.URL {
 // RemoveServer removes the given server from all the Balancer,
 // and updates the status of the server to "DOWN".
 func (b Balancers) RemoveServer(u *url.URL) error {
-	for _, lb := range b {
-		if err := lb.RemoveServer(u); err != nil {
-			return err
-		}
-	}
-	return nil
+	return b.apply(func(lb Balancer) error {
+		return lb.RemoveServer(u)
+	})
 }
 
 // UpsertServer adds the given server to all the Balancer,
 // and updates the status of the server to "UP".
 func (b Balancers) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	return b.apply(func(lb Balancer) error {
+		return lb.UpsertServer(u, options...)
+	})
+}
+
+// ServerWeight returns the weight of the given server in the first Balancer knowing it, the primaries first.
+func (b Balancers) ServerWeight(u *url.URL) (int, bool) {
+	for _, lb := range b.ordered() {
+		if mirror, ok := lb.(*MirrorBalancer); ok {
+			lb = mirror.Balancer
+		}
+
+		wb, ok := lb.(weightedBalancer)
+		if !ok {
+			continue
+		}
+
+		if weight, ok := wb.ServerWeight(u); ok {
+			return weight, true
+		}
+	}
+
+	return 0, false
+}
+
+// RecordCheck records the result of the last health check of the given server
+// in all the Balancer keeping track of them.
+func (b Balancers) RecordCheck(u *url.URL, check runtime.ServerCheck) {
 	for _, lb := range b {
-		if err := lb.UpsertServer(u, options...); err != nil {
+		if mirror, ok := lb.(*MirrorBalancer); ok {
+			lb = mirror.Balancer
+		}
+
+		if recorder, ok := lb.(CheckRecorder); ok {
+			recorder.RecordCheck(u, check)
+		}
+	}
+}
+
+// apply calls fn on the primaries, stopping at the first error,
+// and then on the mirrors, whose errors are only logged.
+func (b Balancers) apply(fn func(lb Balancer) error) error {
+	for _, lb := range b.ordered() {
+		err := fn(lb)
+		if err == nil {
+			continue
+		}
+
+		if _, ok := lb.(*MirrorBalancer); !ok {
 			return err
 		}
+
+		log.WithoutContext().Warnf("Unable to update mirror balancer: %v", err)
 	}
 	return nil
 }
 
+// ordered returns the primaries followed by the mirrors, preserving their respective order.
+func (b Balancers) ordered() []Balancer {
+	ordered := make([]Balancer, 0, len(b))
+	var mirrors []Balancer
+	for _, lb := range b {
+		if _, ok := lb.(*MirrorBalancer); ok {
+			mirrors = append(mirrors, lb)
+			continue
+		}
+
+		ordered = append(ordered, lb)
+	}
+
+	return append(ordered, mirrors...)
+}
+
 func serverKey(u *url.URL) string {
 	return u.Path + u.Host + u.Scheme
 }
@@ -0,0 +1,90 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// checkJSON returns an error unless the given response body is a JSON document
+// whose fields, identified by their dot-separated paths, have the given expected values.
+func checkJSON(content []byte, expected map[string]string) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+
+	// The fields are checked in a stable order, so that a server failing on several of them always reports the same one.
+	paths := make([]string, 0, len(expected))
+	for path := range expected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		value, ok := jsonField(document, path)
+		if !ok {
+			return fmt.Errorf("response body has no JSON field %q", path)
+		}
+
+		actual, err := jsonValue(value)
+		if err != nil {
+			return fmt.Errorf("failed to format the JSON field %q: %w", path, err)
+		}
+
+		if actual != expected[path] {
+			return fmt.Errorf("JSON field %q is %q, expected %q", path, actual, expected[path])
+		}
+	}
+
+	return nil
+}
+
+// jsonField returns the field of the given JSON document at the given dot-separated path,
+// where the elements of the arrays are identified by their index, e.g. checks.0.status.
+func jsonField(document interface{}, path string) (interface{}, bool) {
+	value := document
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			field, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			value = field
+
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			value = node[index]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// jsonValue returns the given JSON value as compared to an expected value:
+// the strings are unquoted, while the other values are in their JSON form, e.g. true, 42, or null.
+func jsonValue(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
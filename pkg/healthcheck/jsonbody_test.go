@@ -0,0 +1,80 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckHealth_ExpectedJSON(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		body         string
+		expectedJSON map[string]string
+		expectedErr  string
+	}{
+		{
+			desc:         "matching field",
+			body:         `{"status": "UP"}`,
+			expectedJSON: map[string]string{"status": "UP"},
+		},
+		{
+			desc:         "matching nested fields",
+			body:         `{"status": "UP", "components": {"db": {"status": "UP"}}, "checks": [{"healthy": true, "load": 0.5}]}`,
+			expectedJSON: map[string]string{"components.db.status": "UP", "checks.0.healthy": "true", "checks.0.load": "0.5"},
+		},
+		{
+			desc:         "mismatching value",
+			body:         `{"status": "DOWN"}`,
+			expectedJSON: map[string]string{"status": "UP"},
+			expectedErr:  `JSON field "status" is "DOWN", expected "UP"`,
+		},
+		{
+			desc:         "missing field",
+			body:         `{"checks": []}`,
+			expectedJSON: map[string]string{"checks.0.healthy": "true"},
+			expectedErr:  `response body has no JSON field "checks.0.healthy"`,
+		},
+		{
+			desc:         "invalid JSON",
+			body:         `status: UP`,
+			expectedJSON: map[string]string{"status": "UP"},
+			expectedErr:  "response body is not valid JSON",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				_, _ = rw.Write([]byte(test.body))
+			}))
+			t.Cleanup(server.Close)
+
+			backend, err := NewBackendConfig(Options{
+				Path:         "/health",
+				Timeout:      time.Second,
+				ExpectedJSON: test.expectedJSON,
+			}, "backendName")
+			require.NoError(t, err)
+
+			err = checkHealth(context.Background(), testhelpers.MustParseURL(server.URL), backend)
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -2,25 +2,42 @@ package healthcheck
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/traefik/traefik/v2/pkg/testhelpers"
 	"github.com/vulcand/oxy/roundrobin"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type StartTestServer interface {
 	Start(t *testing.T, done func()) (*url.URL, time.Duration)
 }
 
+// checkOverDelay is the delay after which the check in flight is over, and the next one did not start yet.
+const checkOverDelay = (healthCheckTimeout + healthCheckInterval) / 2
+
+// afterCheck returns a function calling done once the check in flight is over,
+// so that the health check processed its outcome before it is stopped.
+func afterCheck(done func()) func() {
+	return func() { time.AfterFunc(checkOverDelay, done) }
+}
+
 type Status interface {
 	~int | ~int32
 }
@@ -50,7 +67,17 @@ func (s *HealthSequence[T]) IsEmpty() bool {
 
 type GRPCServer struct {
 	status HealthSequence[healthpb.HealthCheckResponse_ServingStatus]
-	done   func()
+	// services holds the status of the named services, the sequence being the status of the overall server.
+	services map[string]healthpb.HealthCheckResponse_ServingStatus
+	// metadata holds the metadata the check requests must carry for the server to report its status.
+	metadata map[string]string
+	// serviceUnknown holds whether the unknown services are reported with the SERVICE_UNKNOWN status.
+	serviceUnknown bool
+	// watch holds the statuses pushed through the Watch streams, which stay open, nil for a single status of the sequence.
+	watch chan healthpb.HealthCheckResponse_ServingStatus
+	// watchUnimplemented makes the server not implement Watch.
+	watchUnimplemented bool
+	done               func()
 }
 
 func newGRPCServer(healthSequence ...healthpb.HealthCheckResponse_ServingStatus) *GRPCServer {
@@ -63,18 +90,94 @@ func newGRPCServer(healthSequence ...healthpb.HealthCheckResponse_ServingStatus)
 	return gRPCService
 }
 
-func (s *GRPCServer) Check(_ context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+// withService registers a named service reporting the given status.
+func (s *GRPCServer) withService(name string, stat healthpb.HealthCheckResponse_ServingStatus) *GRPCServer {
+	if s.services == nil {
+		s.services = make(map[string]healthpb.HealthCheckResponse_ServingStatus)
+	}
+	s.services[name] = stat
+
+	return s
+}
+
+// withMetadata makes the server report NOT_SERVING to the check requests lacking the given metadata.
+func (s *GRPCServer) withMetadata(key, value string) *GRPCServer {
+	if s.metadata == nil {
+		s.metadata = make(map[string]string)
+	}
+	s.metadata[key] = value
+
+	return s
+}
+
+// withServiceUnknown makes the server report the SERVICE_UNKNOWN status for the unknown services, instead of a NotFound error.
+func (s *GRPCServer) withServiceUnknown() *GRPCServer {
+	s.serviceUnknown = true
+
+	return s
+}
+
+// withWatchStream makes the server push the statuses sent to the returned channel through the Watch streams.
+func (s *GRPCServer) withWatchStream() (*GRPCServer, chan<- healthpb.HealthCheckResponse_ServingStatus) {
+	s.watch = make(chan healthpb.HealthCheckResponse_ServingStatus)
+
+	return s, s.watch
+}
+
+// withoutWatch makes the server reply to the Watch requests with the Unimplemented code.
+func (s *GRPCServer) withoutWatch() *GRPCServer {
+	s.watchUnimplemented = true
+
+	return s
+}
+
+func (s *GRPCServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
 	stat := s.status.Pop()
 	if s.status.IsEmpty() {
 		s.done()
 	}
 
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, value := range s.metadata {
+		if values := md.Get(key); len(values) == 0 || values[0] != value {
+			return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+		}
+	}
+
+	if req.Service != "" {
+		serviceStat, ok := s.services[req.Service]
+		switch {
+		case !ok && s.serviceUnknown:
+			serviceStat = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		case !ok:
+			return nil, status.Errorf(codes.NotFound, "unknown service %s", req.Service)
+		}
+		stat = serviceStat
+	}
+
 	return &healthpb.HealthCheckResponse{
 		Status: stat,
 	}, nil
 }
 
 func (s *GRPCServer) Watch(_ *healthpb.HealthCheckRequest, server healthpb.Health_WatchServer) error {
+	if s.watchUnimplemented {
+		return status.Error(codes.Unimplemented, "unimplemented")
+	}
+
+	if s.watch != nil {
+		for {
+			select {
+			case <-server.Context().Done():
+				return nil
+			case stat := <-s.watch:
+				if err := server.Send(&healthpb.HealthCheckResponse{Status: stat}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	stat := s.status.Pop()
 	if s.status.IsEmpty() {
 		s.done()
@@ -95,7 +198,7 @@ func (s *GRPCServer) Start(t *testing.T, done func()) (*url.URL, time.Duration)
 	server := grpc.NewServer()
 	t.Cleanup(server.Stop)
 
-	s.done = done
+	s.done = afterCheck(done)
 
 	healthpb.RegisterHealthServer(server, s)
 
@@ -105,7 +208,55 @@ func (s *GRPCServer) Start(t *testing.T, done func()) (*url.URL, time.Duration)
 	}()
 
 	// Make test timeout dependent on number of expected requests, health check interval, and a safety margin.
-	return testhelpers.MustParseURL("http://" + listener.Addr().String()), time.Duration(len(s.status.sequence)*int(healthCheckInterval) + 500)
+	return testhelpers.MustParseURL("http://" + listener.Addr().String()), time.Duration(len(s.status.sequence)*int(healthCheckInterval)) + checkOverDelay
+}
+
+type TCPServer struct {
+	accept bool
+	done   func()
+	once   sync.Once
+}
+
+// newTCPServer returns a server accepting the TCP connections, or refusing them if accept is false.
+func newTCPServer(accept bool) *TCPServer {
+	return &TCPServer{accept: accept}
+}
+
+func (s *TCPServer) Start(t *testing.T, done func()) (*url.URL, time.Duration) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s.done = func() { s.once.Do(afterCheck(done)) }
+
+	serverURL := testhelpers.MustParseURL("http://" + listener.Addr().String())
+
+	if !s.accept {
+		// Closing the listener makes further connections on its port refused.
+		_ = listener.Close()
+
+		// The initial health check happens before done is called.
+		s.done()
+
+		return serverURL, healthCheckInterval
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_ = conn.Close()
+			s.done()
+		}
+	}()
+
+	return serverURL, healthCheckInterval
 }
 
 type HTTPServer struct {
@@ -138,13 +289,13 @@ func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
 func (s *HTTPServer) Start(t *testing.T, done func()) (*url.URL, time.Duration) {
 	t.Helper()
 
-	s.done = done
+	s.done = afterCheck(done)
 
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
 
 	// Make test timeout dependent on number of expected requests, health check interval, and a safety margin.
-	return testhelpers.MustParseURL(ts.URL), time.Duration(len(s.status.sequence)*int(healthCheckInterval) + 500)
+	return testhelpers.MustParseURL(ts.URL), time.Duration(len(s.status.sequence)*int(healthCheckInterval)) + checkOverDelay
 }
 
 type testLoadBalancer struct {
@@ -203,3 +354,145 @@ func (lb *testLoadBalancer) removeServer(u *url.URL) {
 
 	lb.servers = append(lb.servers[:i], lb.servers[i+1:]...)
 }
+
+// failingLoadBalancer is a Balancer failing to update its servers.
+type failingLoadBalancer struct {
+	servers []*url.URL
+}
+
+func (lb *failingLoadBalancer) RemoveServer(_ *url.URL) error {
+	return errors.New("remove server failure")
+}
+
+func (lb *failingLoadBalancer) UpsertServer(_ *url.URL, _ ...roundrobin.ServerOption) error {
+	return errors.New("upsert server failure")
+}
+
+func (lb *failingLoadBalancer) Servers() []*url.URL {
+	return lb.servers
+}
+
+type UDPServer struct {
+	reply bool
+	done  func()
+	once  sync.Once
+}
+
+// newUDPServer returns a server echoing the datagrams it receives, or ignoring them if reply is false.
+func newUDPServer(reply bool) *UDPServer {
+	return &UDPServer{reply: reply}
+}
+
+func (s *UDPServer) Start(t *testing.T, done func()) (*url.URL, time.Duration) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	s.done = func() { s.once.Do(afterCheck(done)) }
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if s.reply {
+				_, _ = conn.WriteTo(buf[:n], addr)
+			}
+			s.done()
+		}
+	}()
+
+	return testhelpers.MustParseURL("udp://" + conn.LocalAddr().String()), healthCheckInterval
+}
+
+// DNSServer is a DNS server resolving any name to 127.0.0.1, or to its ip when set, while resolvable, and answering NXDOMAIN otherwise.
+type DNSServer struct {
+	resolvable atomic.Bool
+	ip         atomic.Pointer[net.IP]
+}
+
+func (s *DNSServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	switch {
+	case !s.resolvable.Load():
+		resp.Rcode = dns.RcodeNameError
+	case req.Question[0].Qtype == dns.TypeA:
+		ip := net.IPv4(127, 0, 0, 1)
+		if p := s.ip.Load(); p != nil {
+			ip = *p
+		}
+
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   ip,
+		})
+	}
+
+	_ = w.WriteMsg(resp)
+}
+
+// Resolver starts the server, and returns a resolver sending all its queries to it.
+func (s *DNSServer) Resolver(t *testing.T) *net.Resolver {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	server := &dns.Server{PacketConn: conn, Handler: s}
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	go func() { _ = server.ActivateAndServe() }()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+}
+
+// seriesCounter is a metrics.Counter keeping the value of each of its label series.
+type seriesCounter struct {
+	mu          *sync.Mutex
+	series      map[string]float64
+	labelValues []string
+}
+
+func newSeriesCounter() *seriesCounter {
+	return &seriesCounter{mu: &sync.Mutex{}, series: make(map[string]float64)}
+}
+
+func (c *seriesCounter) With(labelValues ...string) gokitmetrics.Counter {
+	return &seriesCounter{
+		mu:          c.mu,
+		series:      c.series,
+		labelValues: append(append([]string(nil), c.labelValues...), labelValues...),
+	}
+}
+
+func (c *seriesCounter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.series[strings.Join(c.labelValues, " ")] += delta
+}
+
+// values returns the value of each label series, keyed by their space-separated label values.
+func (c *seriesCounter) values() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	values := make(map[string]float64, len(c.series))
+	for series, value := range c.series {
+		values[series] = value
+	}
+	return values
+}
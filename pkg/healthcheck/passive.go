@@ -0,0 +1,198 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// outcomeWindow holds the last request outcomes of a server.
+type outcomeWindow struct {
+	failed []bool
+	next   int
+	count  int
+	errors int
+}
+
+func newOutcomeWindow(size int) *outcomeWindow {
+	return &outcomeWindow{failed: make([]bool, size)}
+}
+
+// add records an outcome, evicting the oldest one once the window is full.
+func (w *outcomeWindow) add(ok bool) {
+	if w.count == len(w.failed) {
+		if w.failed[w.next] {
+			w.errors--
+		}
+	} else {
+		w.count++
+	}
+
+	w.failed[w.next] = !ok
+	if !ok {
+		w.errors++
+	}
+
+	w.next = (w.next + 1) % len(w.failed)
+}
+
+// full returns whether the window holds as many outcomes as its size.
+func (w *outcomeWindow) full() bool {
+	return w.count == len(w.failed)
+}
+
+// errorRatio returns the ratio of failed outcomes in the window.
+func (w *outcomeWindow) errorRatio() float64 {
+	if w.count == 0 {
+		return 0
+	}
+
+	return float64(w.errors) / float64(w.count)
+}
+
+// ReportResult reports the outcome of a request forwarded to the given server, for the passive health check.
+// Once the window of the last outcomes is full, the server is removed from the load-balancer if its error ratio exceeds the PassiveErrorRatio,
+// unless it is kept, as by the active health check, e.g. during the StartupGracePeriod or as the last server with KeepLastHealthy.
+// The active health check is in charge of returning it to the load-balancer.
+func (b *BackendConfig) ReportResult(server *url.URL, ok bool) {
+	if b.PassiveWindow <= 0 || b.PassiveErrorRatio <= 0 {
+		return
+	}
+
+	errorRatio, failed := b.recordOutcome(server, ok)
+	if !failed {
+		return
+	}
+
+	ctx := log.With(context.Background(), log.Str(log.ServiceName, b.name))
+	logger := log.FromContext(ctx)
+
+	if b.ShadowMode {
+		// In shadow mode, the server is neither removed nor tracked as disabled,
+		// so that its next outcomes keep being reported, and the active health check does not return it.
+		logger.Warnf("Shadow passive health check failed, would remove from server list. Backend: %q URL: %q Weight: %d Error ratio: %.2f",
+			b.name, server.String(), serverWeight(b.LB, server), errorRatio)
+		return
+	}
+
+	b.stateMu.Lock()
+	defer b.stateMu.Unlock()
+
+	now := b.getClock().Now()
+	if reason := b.keepReason(now); reason != "" {
+		logger.Warnf("Passive health check failed, keeping the server in the server list %s. Backend: %q URL: %q Error ratio: %.2f",
+			reason, b.name, server.String(), errorRatio)
+		return
+	}
+
+	weight, disabled := b.failServer(ctx, server, now, fmt.Sprintf("passive health check error ratio %.2f", errorRatio))
+	if !disabled {
+		// Already removed by the active health check.
+		return
+	}
+
+	logger.Warnf("Passive health check failed, removing from server list. Backend: %q URL: %q Weight: %d Error ratio: %.2f",
+		b.name, server.String(), weight, errorRatio)
+}
+
+// recordOutcome records the outcome of a request forwarded to the given server,
+// and returns the error ratio of its window, and whether it exceeds the PassiveErrorRatio, which resets the window.
+// The outcomes of the disabled servers are ignored.
+func (b *BackendConfig) recordOutcome(server *url.URL, ok bool) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.isDisabled(server) {
+		return 0, false
+	}
+
+	key := server.String()
+
+	if b.outcomes == nil {
+		b.outcomes = make(map[string]*outcomeWindow)
+	}
+
+	window, exists := b.outcomes[key]
+	if !exists {
+		window = newOutcomeWindow(b.PassiveWindow)
+		b.outcomes[key] = window
+	}
+
+	window.add(ok)
+
+	if !window.full() || window.errorRatio() <= b.PassiveErrorRatio {
+		return window.errorRatio(), false
+	}
+
+	// The server starts with a fresh window once returned to the load-balancer.
+	delete(b.outcomes, key)
+
+	return window.errorRatio(), true
+}
+
+// PassiveReporter is a handler reporting the outcomes of the requests to the passive health check of a backend,
+// meant to wrap the handler forwarding the requests load-balanced to the servers.
+// The responses with a 5xx status code are reported as failures.
+type PassiveReporter struct {
+	next    http.Handler
+	hc      *HealthCheck
+	backend string
+}
+
+// NewPassiveReporter returns a new PassiveReporter, reporting to the given backend of the given HealthCheck.
+// The backend is looked up on each request, so that the outcomes are reported to its current configuration.
+func NewPassiveReporter(next http.Handler, hc *HealthCheck, backend string) *PassiveReporter {
+	return &PassiveReporter{next: next, hc: hc, backend: backend}
+}
+
+func (p *PassiveReporter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The load-balancer sets the URL of the request to the one of the selected server.
+	server := *req.URL
+
+	recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+	p.next.ServeHTTP(recorder, req)
+
+	p.hc.backendsMu.RLock()
+	backend := p.hc.Backends[p.backend]
+	p.hc.backendsMu.RUnlock()
+
+	if backend != nil {
+		backend.ReportResult(&server, recorder.status < http.StatusInternalServerError)
+	}
+}
+
+// statusRecorder captures the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hijacks the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,206 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestOutcomeWindow(t *testing.T) {
+	window := newOutcomeWindow(3)
+
+	window.add(false)
+	assert.False(t, window.full())
+	assert.Equal(t, 1.0, window.errorRatio())
+
+	window.add(true)
+	window.add(true)
+	assert.True(t, window.full())
+	assert.InDelta(t, 1.0/3, window.errorRatio(), 0.001)
+
+	// The oldest outcome, the failure, is evicted.
+	window.add(true)
+	assert.True(t, window.full())
+	assert.Equal(t, 0.0, window.errorRatio())
+}
+
+func TestReportResult(t *testing.T) {
+	server := newHTTPServer(http.StatusOK)
+	serverURL, _ := server.Start(t, func() {})
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		servers: []*url.URL{serverURL},
+	}
+
+	backend, err := NewBackendConfig(Options{
+		Path:              "/path",
+		Interval:          healthCheckInterval,
+		Timeout:           healthCheckTimeout,
+		PassiveWindow:     4,
+		PassiveErrorRatio: 0.5,
+		LB:                lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	// The error ratio of the window does not exceed the threshold.
+	for _, ok := range []bool{true, false, false, true} {
+		backend.ReportResult(serverURL, ok)
+	}
+	assert.Equal(t, 0, lb.numRemovedServers)
+	assert.Empty(t, backend.disabledURLs)
+
+	// The window now holds three failures out of four outcomes.
+	backend.ReportResult(serverURL, false)
+	assert.Equal(t, 1, lb.numRemovedServers)
+	require.Len(t, backend.disabledURLs, 1)
+	assert.Equal(t, serverURL, backend.disabledURLs[0].url)
+
+	// Outcomes reported for a disabled server are ignored.
+	backend.ReportResult(serverURL, false)
+	assert.Equal(t, 1, lb.numRemovedServers)
+	assert.Len(t, backend.disabledURLs, 1)
+
+	// The active health check brings the server back.
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+	check.checkServersLB(context.Background(), backend)
+
+	assert.Equal(t, 1, lb.numUpsertedServers)
+	assert.Empty(t, backend.disabledURLs)
+}
+
+func TestReportResult_disabled(t *testing.T) {
+	serverURL := testhelpers.MustParseURL("http://127.0.0.1:8080")
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		servers: []*url.URL{serverURL},
+	}
+
+	backend, err := NewBackendConfig(Options{LB: lb}, "backendName")
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		backend.ReportResult(serverURL, false)
+	}
+
+	assert.Equal(t, 0, lb.numRemovedServers)
+	assert.Empty(t, backend.disabledURLs)
+}
+
+func TestReportResult_shadowMode(t *testing.T) {
+	serverURL := testhelpers.MustParseURL("http://127.0.0.1:8080")
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		servers: []*url.URL{serverURL},
+	}
+
+	backend, err := NewBackendConfig(Options{
+		PassiveWindow:     2,
+		PassiveErrorRatio: 0.5,
+		ShadowMode:        true,
+		LB:                lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	backend.ReportResult(serverURL, false)
+	backend.ReportResult(serverURL, false)
+
+	assert.Equal(t, 0, lb.numRemovedServers)
+	assert.Empty(t, backend.disabledURLs)
+
+	// The outcomes keep being reported, with a fresh window.
+	backend.ReportResult(serverURL, false)
+	require.Contains(t, backend.outcomes, serverURL.String())
+	assert.Equal(t, 1, backend.outcomes[serverURL.String()].count)
+}
+
+func TestReportResult_keepLastHealthy(t *testing.T) {
+	serverURL := testhelpers.MustParseURL("http://127.0.0.1:8080")
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		servers: []*url.URL{serverURL},
+	}
+
+	backend, err := NewBackendConfig(Options{
+		PassiveWindow:     2,
+		PassiveErrorRatio: 0.5,
+		KeepLastHealthy:   true,
+		LB:                lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	backend.ReportResult(serverURL, false)
+	backend.ReportResult(serverURL, false)
+
+	// The last server is kept, as by the active health check.
+	assert.Equal(t, 0, lb.numRemovedServers)
+	assert.Empty(t, backend.disabledURLs)
+}
+
+func TestPassiveReporter(t *testing.T) {
+	serverURL := testhelpers.MustParseURL("http://127.0.0.1:8080")
+	otherURL := testhelpers.MustParseURL("http://127.0.0.1:8081")
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		servers: []*url.URL{serverURL, otherURL},
+	}
+
+	registry := &fakeRegistry{}
+	events := make(chan StatusEvent, 1)
+
+	backend, err := NewBackendConfig(Options{
+		PassiveWindow:     2,
+		PassiveErrorRatio: 0.5,
+		Registry:          registry,
+		EventChan:         events,
+		LB:                lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	check := HealthCheck{Backends: map[string]*BackendConfig{"backendName": backend}}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Host == serverURL.Host {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+	reporter := NewPassiveReporter(next, &check, "backendName")
+
+	serve := func(server *url.URL) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		// The load-balancer sets the URL of the request to the one of the selected server.
+		req.URL = server
+		reporter.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(otherURL)
+	serve(otherURL)
+	serve(serverURL)
+	serve(serverURL)
+
+	assert.Equal(t, []*url.URL{otherURL}, lb.Servers())
+	require.Len(t, backend.disabledURLs, 1)
+	assert.Equal(t, serverURL, backend.disabledURLs[0].url)
+	assert.Equal(t, []string{"deregister " + serverURL.String()}, registry.calls)
+
+	event := <-events
+	assert.Equal(t, serverDown, event.NewStatus)
+}
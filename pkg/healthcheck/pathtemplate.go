@@ -0,0 +1,56 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// pathTokenRegexp matches the tokens of the check paths and addresses, e.g. {host}.
+var pathTokenRegexp = regexp.MustCompile(`\{(\w*)\}`)
+
+// validatePathTemplate returns an error when the given check path or address holds a token other than {host}, {port}, and {scheme}.
+func validatePathTemplate(path string) error {
+	for _, match := range pathTokenRegexp.FindAllStringSubmatch(path, -1) {
+		switch match[1] {
+		case "host", "port", "scheme":
+		default:
+			return fmt.Errorf("unknown token %s, expected {host}, {port}, or {scheme}", match[0])
+		}
+	}
+
+	return nil
+}
+
+// expandPath substitutes the {host}, {port}, and {scheme} tokens of the given check path with the ones of the given server URL.
+// When the server URL has no port, {port} is the default port of its scheme.
+func expandPath(path string, serverURL *url.URL) string {
+	return expandTokens(path, serverURL, url.PathEscape)
+}
+
+// expandAddress substitutes the {host}, {port}, and {scheme} tokens of the given address with the ones of the given server URL.
+func expandAddress(address string, serverURL *url.URL) string {
+	return expandTokens(address, serverURL, func(host string) string { return host })
+}
+
+func expandTokens(template string, serverURL *url.URL, escapeHost func(string) string) string {
+	return pathTokenRegexp.ReplaceAllStringFunc(template, func(token string) string {
+		switch token {
+		case "{host}":
+			return escapeHost(serverURL.Hostname())
+		case "{port}":
+			if port := serverURL.Port(); port != "" {
+				return port
+			}
+			if serverURL.Scheme == "https" {
+				return "443"
+			}
+			return "80"
+		case "{scheme}":
+			return serverURL.Scheme
+		default:
+			// Rejected by the validation of the options.
+			return token
+		}
+	})
+}
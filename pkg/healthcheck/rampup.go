@@ -0,0 +1,91 @@
+package healthcheck
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// rampUp is the slow start of a server returned to the load-balancer, over the RampUpDuration.
+type rampUp struct {
+	start      time.Time
+	fullWeight int
+}
+
+// startRampUp records the return of the given server to the load-balancer with the given full weight,
+// and returns the weight it starts with.
+func (b *BackendConfig) startRampUp(u *url.URL, fullWeight int, now time.Time) int {
+	if b.RampUpDuration <= 0 || fullWeight <= 1 {
+		return fullWeight
+	}
+
+	if b.rampUps == nil {
+		b.rampUps = make(map[string]*rampUp)
+	}
+	b.rampUps[u.String()] = &rampUp{start: now, fullWeight: fullWeight}
+
+	return rampWeight(fullWeight, 0, b.RampUpDuration)
+}
+
+// stepRampUp increases the weight of the given server ramping up, according to the time elapsed since its return,
+// and ends its ramp-up once it reached its full weight.
+func (b *BackendConfig) stepRampUp(ctx context.Context, u *url.URL, now time.Time) {
+	key := u.String()
+	ramp, ok := b.rampUps[key]
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	disabled := b.isDisabled(u)
+	b.mu.Unlock()
+
+	if disabled {
+		// Removed by the passive health check in the meantime.
+		delete(b.rampUps, key)
+		return
+	}
+
+	weight := rampWeight(ramp.fullWeight, now.Sub(ramp.start), b.RampUpDuration)
+	if weight == ramp.fullWeight {
+		delete(b.rampUps, key)
+	}
+
+	if weight == serverWeight(b.LB, u) {
+		return
+	}
+
+	log.FromContext(ctx).Debugf("Health check ramp-up: increasing the weight of the server. Backend: %q URL: %q Weight: %d", b.name, key, weight)
+	if err := b.LB.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
+
+// stopRampUp ends the ramp-up of the given server, if any,
+// and returns its full weight, defaulting to its weight in the load-balancer.
+func (b *BackendConfig) stopRampUp(u *url.URL) int {
+	if ramp, ok := b.rampUps[u.String()]; ok {
+		delete(b.rampUps, u.String())
+		return ramp.fullWeight
+	}
+
+	return serverWeight(b.LB, u)
+}
+
+// rampWeight returns the weight of a server ramping up to the given full weight, once the given time elapsed,
+// increasing linearly from 1 to the full weight over the given duration.
+func rampWeight(fullWeight int, elapsed, duration time.Duration) int {
+	if elapsed >= duration {
+		return fullWeight
+	}
+
+	weight := int(int64(fullWeight) * int64(elapsed) / int64(duration))
+	if weight < 1 {
+		return 1
+	}
+
+	return weight
+}
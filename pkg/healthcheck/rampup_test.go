@@ -0,0 +1,123 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestCheckServersLB_RampUp(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if healthy.Load() {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+
+	rr, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+	require.NoError(t, rr.UpsertServer(serverURL, roundrobin.Weight(10)))
+
+	backend, err := NewBackendConfig(Options{
+		Path:           "/health",
+		Interval:       time.Hour,
+		Timeout:        time.Second,
+		RampUpDuration: 4 * time.Hour,
+		LB:             NewLBStatusUpdater(rr, &runtime.ServiceInfo{}, nil),
+	}, "backendName")
+	require.NoError(t, err)
+
+	clock := newFakeClock()
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+	check.SetClock(clock)
+
+	checkServers := func(up bool) {
+		t.Helper()
+
+		healthy.Store(up)
+		check.probes.reset()
+		check.checkServersLB(context.Background(), backend)
+	}
+
+	checkServers(false)
+	_, ok := rr.ServerWeight(serverURL)
+	require.False(t, ok)
+
+	// The server returns to the load-balancer with the minimal weight.
+	checkServers(true)
+	weight, ok := rr.ServerWeight(serverURL)
+	require.True(t, ok)
+	assert.Equal(t, 1, weight)
+
+	for _, expectedWeight := range []int{2, 5, 7, 10, 10} {
+		clock.Advance(time.Hour)
+		checkServers(true)
+
+		weight, ok = rr.ServerWeight(serverURL)
+		require.True(t, ok)
+		assert.Equal(t, expectedWeight, weight)
+	}
+
+	// The server removed again returns with its full weight as the weight to ramp up to.
+	checkServers(false)
+	checkServers(true)
+	weight, ok = rr.ServerWeight(serverURL)
+	require.True(t, ok)
+	assert.Equal(t, 1, weight)
+	assert.Equal(t, 10, backend.rampUps[serverURL.String()].fullWeight)
+}
+
+func TestRampWeight(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		elapsed        time.Duration
+		expectedWeight int
+	}{
+		{
+			desc:           "start of the ramp-up",
+			expectedWeight: 1,
+		},
+		{
+			desc:           "middle of the ramp-up",
+			elapsed:        5 * time.Second,
+			expectedWeight: 50,
+		},
+		{
+			desc:           "end of the ramp-up",
+			elapsed:        10 * time.Second,
+			expectedWeight: 100,
+		},
+		{
+			desc:           "after the ramp-up",
+			elapsed:        time.Minute,
+			expectedWeight: 100,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expectedWeight, rampWeight(100, test.elapsed, 10*time.Second))
+		})
+	}
+}
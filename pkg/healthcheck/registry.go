@@ -0,0 +1,42 @@
+package healthcheck
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// Registry is an external service registry, e.g. Consul or etcd, kept consistent with the health of the servers:
+// the servers removed from the load-balancer by the health check are deregistered, and registered again once returned.
+// Its methods are called one at a time, from the goroutine checking the backend, or from the requests tripping the passive health check,
+// and should not block for long.
+type Registry interface {
+	Register(server *url.URL) error
+	Deregister(server *url.URL) error
+}
+
+// nopRegistry is the Registry of the backends without an external service registry.
+type nopRegistry struct{}
+
+func (nopRegistry) Register(*url.URL) error {
+	return nil
+}
+
+func (nopRegistry) Deregister(*url.URL) error {
+	return nil
+}
+
+// updateRegistry registers the given server returned to the load-balancer in the Registry, or deregisters it once removed.
+func (b *BackendConfig) updateRegistry(ctx context.Context, u *url.URL, up bool) {
+	var err error
+	if up {
+		err = b.Registry.Register(u)
+	} else {
+		err = b.Registry.Deregister(u)
+	}
+
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to update the registry of the server %s of the backend %q: %v", u.String(), b.name, err)
+	}
+}
@@ -0,0 +1,84 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckServersLB_Registry(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if healthy.Load() {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{serverURL}}
+	registry := &fakeRegistry{}
+
+	backend, err := NewBackendConfig(Options{
+		Path:     "/path",
+		Interval: healthCheckInterval,
+		Timeout:  healthCheckTimeout,
+		Registry: registry,
+		LB:       lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+
+	checkServers := func(up bool) {
+		t.Helper()
+
+		healthy.Store(up)
+		check.probes.reset()
+		check.checkServersLB(context.Background(), backend)
+	}
+
+	// The registry is only updated on the transitions.
+	checkServers(true)
+	checkServers(false)
+	checkServers(false)
+	checkServers(true)
+	checkServers(true)
+
+	assert.Equal(t, []string{"deregister " + serverURL.String(), "register " + serverURL.String()}, registry.calls)
+}
+
+func TestNewBackendConfig_nopRegistry(t *testing.T) {
+	backend, err := NewBackendConfig(Options{}, "backendName")
+	require.NoError(t, err)
+
+	assert.Equal(t, nopRegistry{}, backend.Registry)
+}
+
+// fakeRegistry is a Registry recording its calls.
+type fakeRegistry struct {
+	calls []string
+}
+
+func (r *fakeRegistry) Register(server *url.URL) error {
+	r.calls = append(r.calls, "register "+server.String())
+	return nil
+}
+
+func (r *fakeRegistry) Deregister(server *url.URL) error {
+	r.calls = append(r.calls, "deregister "+server.String())
+	return nil
+}
@@ -0,0 +1,36 @@
+package healthcheck
+
+import (
+	"math"
+	"net/url"
+	"sort"
+)
+
+// sample returns the servers checked on the current cycle, with a SampleFraction:
+// the fraction of the given servers which were not checked for the longest time,
+// so that all of them are checked over ceil(1/SampleFraction) cycles.
+func (b *BackendConfig) sample(servers []*url.URL) []*url.URL {
+	if b.SampleFraction <= 0 || b.SampleFraction >= 1 || len(servers) == 0 {
+		return servers
+	}
+
+	b.sampleCycle++
+	if b.sampledAt == nil {
+		b.sampledAt = make(map[string]int)
+	}
+
+	sampled := make([]*url.URL, len(servers))
+	copy(sampled, servers)
+
+	// The servers never checked come first, as their last cycle is zero.
+	sort.SliceStable(sampled, func(i, j int) bool {
+		return b.sampledAt[sampled[i].String()] < b.sampledAt[sampled[j].String()]
+	})
+
+	sampled = sampled[:int(math.Ceil(b.SampleFraction*float64(len(sampled))))]
+	for _, u := range sampled {
+		b.sampledAt[u.String()] = b.sampleCycle
+	}
+
+	return sampled
+}
@@ -0,0 +1,117 @@
+package healthcheck
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckServersLB_SampleFraction(t *testing.T) {
+	const (
+		serverCount    = 10
+		sampleFraction = 0.3
+	)
+
+	var mu sync.Mutex
+	probes := make(map[string]int)
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		probes[req.Host]++
+		mu.Unlock()
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	for i := 0; i < serverCount; i++ {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		lb.servers = append(lb.servers, testhelpers.MustParseURL(server.URL))
+	}
+
+	backend, err := NewBackendConfig(Options{
+		Path:           "/health",
+		Interval:       healthCheckInterval,
+		Timeout:        healthCheckTimeout,
+		SampleFraction: sampleFraction,
+		LB:             lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+
+	perCycle := int(math.Ceil(sampleFraction * serverCount))
+	cycles := int(math.Ceil(1 / sampleFraction))
+
+	for round := 0; round < 3; round++ {
+		mu.Lock()
+		for host := range probes {
+			delete(probes, host)
+		}
+		mu.Unlock()
+
+		for i := 0; i < cycles; i++ {
+			mu.Lock()
+			before := totalProbes(probes)
+			mu.Unlock()
+
+			check.probes.reset()
+			check.checkServersLB(context.Background(), backend)
+
+			mu.Lock()
+			assert.LessOrEqual(t, totalProbes(probes)-before, perCycle)
+			mu.Unlock()
+		}
+
+		// Each server is probed within ceil(1/fraction) cycles.
+		mu.Lock()
+		for _, server := range lb.servers {
+			assert.NotZero(t, probes[server.Host], server.String())
+		}
+		mu.Unlock()
+	}
+}
+
+func TestBackendConfig_sample(t *testing.T) {
+	servers := []*url.URL{
+		testhelpers.MustParseURL("http://10.0.0.1"),
+		testhelpers.MustParseURL("http://10.0.0.2"),
+		testhelpers.MustParseURL("http://10.0.0.3"),
+		testhelpers.MustParseURL("http://10.0.0.4"),
+	}
+
+	backend := &BackendConfig{Options: Options{SampleFraction: 0.5}}
+
+	// The servers not checked for the longest time come first.
+	assert.Equal(t, servers[:2], backend.sample(servers))
+	assert.Equal(t, servers[2:], backend.sample(servers))
+	assert.Equal(t, servers[:2], backend.sample(servers))
+
+	backend.SampleFraction = 0
+	assert.Equal(t, servers, backend.sample(servers))
+}
+
+func TestNewBackendConfig_SampleFraction(t *testing.T) {
+	_, err := NewBackendConfig(Options{SampleFraction: 1.5}, "backendName")
+	assert.Error(t, err)
+}
+
+func totalProbes(counts map[string]int) int {
+	var total int
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
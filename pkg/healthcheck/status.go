@@ -0,0 +1,92 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// StatusReporter should be implemented by a Balancer keeping track of the statuses of its servers,
+// along with the results of their last health check.
+type StatusReporter interface {
+	ServerStatuses() map[string]runtime.ServerStatus
+}
+
+// serverHealth is the health of a server, as served by the StatusHandler.
+type serverHealth struct {
+	Status     string     `json:"status,omitempty"`
+	LastCheck  *time.Time `json:"lastCheck,omitempty"`
+	LastReason string     `json:"lastReason,omitempty"`
+	LastError  string     `json:"lastError,omitempty"`
+}
+
+// StatusHandler returns a handler serving, as JSON, the health of the servers of all the backends, keyed by backend name and server URL.
+// Only the servers of the backends whose load-balancer implements StatusReporter are served.
+func (hc *HealthCheck) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		hc.backendsMu.RLock()
+		result := make(map[string]map[string]serverHealth, len(hc.Backends))
+		for name, backend := range hc.Backends {
+			reporter, ok := backend.LB.(StatusReporter)
+			if !ok {
+				continue
+			}
+
+			servers := make(map[string]serverHealth)
+			for serverURL, status := range reporter.ServerStatuses() {
+				health := serverHealth{Status: status.Status}
+				if status.LastCheck != nil {
+					checkedAt := status.LastCheck.CheckedAt
+					health.LastCheck = &checkedAt
+					health.LastReason = status.LastCheck.Reason
+					health.LastError = status.LastCheck.Error
+				}
+				servers[serverURL] = health
+			}
+			result[name] = servers
+		}
+		hc.backendsMu.RUnlock()
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(rw).Encode(result); err != nil {
+			log.FromContext(req.Context()).Error(err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ServerStatuses returns the statuses of the servers recorded in the ServiceInfo,
+// along with the results of their last health check.
+func (lb *LbStatusUpdater) ServerStatuses() map[string]runtime.ServerStatus {
+	if lb.serviceInfo == nil {
+		return nil
+	}
+	return lb.serviceInfo.GetAllServerStatus()
+}
+
+// ServerStatuses returns the statuses of the servers recorded by the Balancers,
+// the ones recorded by the primaries taking precedence over the ones recorded by the mirrors.
+func (b Balancers) ServerStatuses() map[string]runtime.ServerStatus {
+	statuses := make(map[string]runtime.ServerStatus)
+	for _, lb := range b.ordered() {
+		if mirror, ok := lb.(*MirrorBalancer); ok {
+			lb = mirror.Balancer
+		}
+
+		reporter, ok := lb.(StatusReporter)
+		if !ok {
+			continue
+		}
+
+		for serverURL, status := range reporter.ServerStatuses() {
+			if _, exists := statuses[serverURL]; !exists {
+				statuses[serverURL] = status
+			}
+		}
+	}
+	return statuses
+}
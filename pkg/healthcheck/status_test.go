@@ -0,0 +1,65 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestHealthCheck_StatusHandler(t *testing.T) {
+	upURL, _ := newHTTPServer(http.StatusOK).Start(t, func() {})
+	downURL, _ := newHTTPServer(http.StatusServiceUnavailable).Start(t, func() {})
+
+	rr, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+
+	serviceInfo := &runtime.ServiceInfo{}
+	for _, serverURL := range []string{upURL.String(), downURL.String()} {
+		require.NoError(t, rr.UpsertServer(testhelpers.MustParseURL(serverURL)))
+		serviceInfo.UpdateServerStatus(serverURL, serverUp)
+	}
+
+	backend, err := NewBackendConfig(Options{
+		Path:     "/path",
+		Interval: healthCheckInterval,
+		Timeout:  healthCheckTimeout,
+		LB:       NewLBStatusUpdater(rr, serviceInfo, nil),
+	}, "backendName")
+	require.NoError(t, err)
+
+	check := HealthCheck{
+		Backends: map[string]*BackendConfig{"backendName": backend},
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+	check.checkServersLB(context.Background(), backend)
+
+	rec := httptest.NewRecorder()
+	check.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var result map[string]map[string]serverHealth
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+
+	require.Contains(t, result, "backendName")
+	servers := result["backendName"]
+	require.Len(t, servers, 2)
+
+	assert.Equal(t, serverUp, servers[upURL.String()].Status)
+	assert.NotNil(t, servers[upURL.String()].LastCheck)
+	assert.Empty(t, servers[upURL.String()].LastReason)
+
+	assert.Equal(t, serverDown, servers[downURL.String()].Status)
+	assert.NotNil(t, servers[downURL.String()].LastCheck)
+	assert.Equal(t, string(failureStatus), servers[downURL.String()].LastReason)
+	assert.NotEmpty(t, servers[downURL.String()].LastError)
+}
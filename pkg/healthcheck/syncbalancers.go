@@ -0,0 +1,102 @@
+package healthcheck
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// SyncBalancers is a set of Balancers which can be swapped atomically, e.g. when a reconfiguration changes them,
+// while the health check updates it: once Swap returns, no update lands on the former Balancers.
+type SyncBalancers struct {
+	mu        sync.RWMutex
+	balancers Balancers
+}
+
+// NewSyncBalancers returns a SyncBalancers holding the given Balancers.
+func NewSyncBalancers(balancers ...Balancer) *SyncBalancers {
+	return &SyncBalancers{balancers: balancers}
+}
+
+// Swap replaces the Balancers of the set, once the updates in flight are over, and returns the former ones.
+func (b *SyncBalancers) Swap(balancers Balancers) Balancers {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	former := b.balancers
+	b.balancers = balancers
+	return former
+}
+
+// Close empties the set, once the updates in flight are over,
+// so that the updates coming later, e.g. from the checks of a former configuration, are dropped.
+func (b *SyncBalancers) Close() {
+	b.Swap(nil)
+}
+
+// Balancers returns the current Balancers of the set.
+func (b *SyncBalancers) Balancers() Balancers {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers
+}
+
+// Servers returns the deduplicated server URLs from all the current Balancers.
+func (b *SyncBalancers) Servers() []*url.URL {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers.Servers()
+}
+
+// RemoveServer removes the given server from all the current Balancers.
+func (b *SyncBalancers) RemoveServer(u *url.URL) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers.RemoveServer(u)
+}
+
+// UpsertServer adds the given server to all the current Balancers.
+func (b *SyncBalancers) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers.UpsertServer(u, options...)
+}
+
+// DrainServer drains the given server in all the current Balancers able to, and removes it from the others.
+func (b *SyncBalancers) DrainServer(u *url.URL, duration time.Duration) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers.DrainServer(u, duration)
+}
+
+// ServerWeight returns the weight of the given server in the first current Balancer knowing it, the primaries first.
+func (b *SyncBalancers) ServerWeight(u *url.URL) (int, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers.ServerWeight(u)
+}
+
+// RecordCheck records the result of the last health check of the given server in all the current Balancers keeping track of them.
+func (b *SyncBalancers) RecordCheck(u *url.URL, check runtime.ServerCheck) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	b.balancers.RecordCheck(u, check)
+}
+
+// ServerStatuses returns the statuses of the servers recorded by the current Balancers.
+func (b *SyncBalancers) ServerStatuses() map[string]runtime.ServerStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.balancers.ServerStatuses()
+}
@@ -0,0 +1,138 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/metrics"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestSyncBalancers_Swap(t *testing.T) {
+	newBalancers := func() Balancers {
+		var balancers Balancers
+		for i := 0; i < 2; i++ {
+			rr, err := roundrobin.New(http.NotFoundHandler())
+			require.NoError(t, err)
+
+			balancers = append(balancers, rr)
+		}
+		return balancers
+	}
+
+	set := NewSyncBalancers(newBalancers()...)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		// Each worker toggles its own server, so that the Balancers of a set stay identical.
+		u := testhelpers.MustParseURL(fmt.Sprintf("http://10.0.0.%d", i+1))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				_ = set.UpsertServer(u)
+				_ = set.RemoveServer(u)
+				_ = set.UpsertServer(u)
+			}
+		}()
+	}
+
+	type snapshot struct {
+		balancers Balancers
+		servers   [][]string
+	}
+
+	var formers []snapshot
+	for i := 0; i < 50; i++ {
+		former := set.Swap(newBalancers())
+		formers = append(formers, snapshot{balancers: former, servers: servers(former)})
+	}
+
+	close(stop)
+	wg.Wait()
+
+	// The former Balancers were not updated once swapped, and their Balancers are identical.
+	for _, former := range formers {
+		assert.Equal(t, former.servers, servers(former.balancers))
+		assert.Equal(t, former.servers[0], former.servers[1])
+	}
+
+	current := servers(set.Balancers())
+	assert.Equal(t, current[0], current[1])
+}
+
+func TestSyncBalancers_Close(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	// The probe ignores the cancellation of the checks, so that it blocks past the stop timeout.
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-release
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Request: req}, nil
+	})
+
+	u := testhelpers.MustParseURL("http://127.0.0.1:8080")
+	rr, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+	require.NoError(t, rr.UpsertServer(u))
+
+	set := NewSyncBalancers(rr)
+	backend, err := NewBackendConfig(Options{
+		Path:      "/health",
+		Interval:  time.Minute,
+		Timeout:   time.Minute,
+		Transport: transport,
+		LB:        set,
+	}, "backend")
+	require.NoError(t, err)
+
+	check := newHealthCheck(metrics.NewVoidRegistry())
+	check.SetBackendsConfiguration(context.Background(), map[string]*BackendConfig{"backend": backend})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, check.Stop(ctx), context.DeadlineExceeded)
+
+	// The set of the former configuration is closed while its check is still in flight.
+	set.Close()
+	close(release)
+	require.NoError(t, check.Stop(context.Background()))
+
+	// The late updates of the former configuration are dropped.
+	require.NoError(t, set.RemoveServer(u))
+	assert.Empty(t, set.Servers())
+	assert.Equal(t, [][]string{{u.String()}}, servers(Balancers{rr}))
+}
+
+// servers returns the sorted servers of each of the given Balancers.
+func servers(balancers Balancers) [][]string {
+	var all [][]string
+	for _, lb := range balancers {
+		urls := []string{}
+		for _, u := range lb.Servers() {
+			urls = append(urls, u.String())
+		}
+		sort.Strings(urls)
+
+		all = append(all, urls)
+	}
+	return all
+}
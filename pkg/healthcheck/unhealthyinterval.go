@@ -0,0 +1,59 @@
+package healthcheck
+
+import (
+	"net/url"
+	"time"
+)
+
+// tickInterval returns the interval between two rounds of checks, without the jitter:
+// the UnhealthyInterval when it is shorter than the Interval, and the Interval otherwise.
+func (b *BackendConfig) tickInterval() time.Duration {
+	if b.UnhealthyInterval > 0 && b.UnhealthyInterval < b.Interval {
+		return b.UnhealthyInterval
+	}
+
+	return b.Interval
+}
+
+// skipHealthy returns whether the check of the given server, which is in the load-balancer, is skipped on the current round of checks.
+// With an UnhealthyInterval, the rounds of checks follow it, while the servers in the load-balancer are only checked every Interval,
+// rounded up to a multiple of the UnhealthyInterval.
+func (b *BackendConfig) skipHealthy(u *url.URL) bool {
+	if b.tickInterval() >= b.Interval {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := u.String()
+	if b.healthySkips[key] > 0 {
+		b.healthySkips[key]--
+		return true
+	}
+
+	b.delayHealthyLocked(key)
+
+	return false
+}
+
+// delayHealthy delays the next check of the given server, returned to the load-balancer on the current round of checks, by an Interval.
+func (b *BackendConfig) delayHealthy(u *url.URL) {
+	if b.tickInterval() >= b.Interval {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.delayHealthyLocked(u.String())
+}
+
+func (b *BackendConfig) delayHealthyLocked(key string) {
+	if b.healthySkips == nil {
+		b.healthySkips = make(map[string]int)
+	}
+
+	tick := b.tickInterval()
+	b.healthySkips[key] = int((b.Interval+tick-1)/tick) - 1
+}
@@ -0,0 +1,87 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckServersLB_UnhealthyInterval(t *testing.T) {
+	var healthyProbes, downProbes atomic.Int32
+	var recovered atomic.Bool
+
+	healthyServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		healthyProbes.Add(1)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(healthyServer.Close)
+
+	downServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		downProbes.Add(1)
+		if recovered.Load() {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(downServer.Close)
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{
+		testhelpers.MustParseURL(healthyServer.URL),
+		testhelpers.MustParseURL(downServer.URL),
+	}}
+
+	backend, err := NewBackendConfig(Options{
+		Path:              "/path",
+		Interval:          time.Hour,
+		UnhealthyInterval: 15 * time.Minute,
+		Timeout:           healthCheckTimeout,
+		LB:                lb,
+	}, "backendName")
+	require.NoError(t, err)
+
+	// The rounds of checks follow the UnhealthyInterval.
+	assert.Equal(t, 15*time.Minute, backend.nextInterval())
+
+	check := HealthCheck{
+		Backends: make(map[string]*BackendConfig),
+		metrics:  metricsHealthcheck{serverUpGauge: &testhelpers.CollectingGauge{}},
+	}
+
+	checkServers := func(rounds int) {
+		t.Helper()
+
+		for i := 0; i < rounds; i++ {
+			check.probes.reset()
+			check.checkServersLB(context.Background(), backend)
+		}
+	}
+
+	// The down server is checked on each round, while the healthy server is checked every four rounds, i.e. every Interval.
+	checkServers(8)
+	assert.Equal(t, int32(8), downProbes.Load())
+	assert.Equal(t, int32(2), healthyProbes.Load())
+	assert.Len(t, lb.Servers(), 1)
+
+	// Once returned to the load-balancer, the server is checked again after an Interval.
+	recovered.Store(true)
+	checkServers(1)
+	assert.Len(t, lb.Servers(), 2)
+	assert.Equal(t, int32(9), downProbes.Load())
+
+	checkServers(3)
+	assert.Equal(t, int32(9), downProbes.Load())
+
+	checkServers(1)
+	assert.Equal(t, int32(10), downProbes.Load())
+	assert.Equal(t, int32(4), healthyProbes.Load())
+}
@@ -0,0 +1,103 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// webSocketGUID is the GUID concatenated to the key of a WebSocket handshake to compute the accept value of the server (RFC 6455).
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// checkHealthWebSocket returns an error if the server does not complete a WebSocket upgrade handshake on the Path.
+// The server is healthy on a 101 Switching Protocols response accepting the handshake, and the connection is closed right away.
+func checkHealthWebSocket(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	req, err := backend.newRequest(serverURL)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	if port, ok := ctx.Value(portContextKey{}).(int); ok && backend.UnixSocket == "" {
+		req.URL.Host = net.JoinHostPort(req.URL.Hostname(), strconv.Itoa(port))
+	}
+
+	req, err = backend.setRequestOptions(req)
+	if err != nil {
+		return err
+	}
+
+	key, err := webSocketKey()
+	if err != nil {
+		return fmt.Errorf("failed to create the WebSocket key: %w", err)
+	}
+
+	// The handshake is a GET request, whatever the Method.
+	req.Method = http.MethodGet
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	if backend.Options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, backend.Options.Timeout)
+		defer cancel()
+	}
+	req = req.WithContext(ctx)
+
+	client := http.Client{Transport: backend.Options.Transport}
+	if backend.Options.HTTPClient != nil {
+		client = *backend.Options.HTTPClient
+	}
+
+	// The upgrade is checked on the Path itself.
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	// For a 101 response, the body is the upgraded connection.
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return &statusCodeError{msg: "received unexpected status code to the WebSocket handshake", statusCode: resp.StatusCode}
+	}
+
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return fmt.Errorf("the server upgraded the connection to %q instead of websocket", resp.Header.Get("Upgrade"))
+	}
+
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		return errors.New("the server did not accept the WebSocket key")
+	}
+
+	return nil
+}
+
+// webSocketKey returns a random key for a WebSocket handshake.
+func webSocketKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// webSocketAccept returns the accept value expected from a server accepting the WebSocket handshake with the given key.
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/testhelpers"
+)
+
+func TestCheckHealth_WebSocket(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		accept    func(key string) string
+		status    int
+		expectErr bool
+	}{
+		{
+			desc:   "upgrade accepted",
+			accept: webSocketAccept,
+			status: http.StatusSwitchingProtocols,
+		},
+		{
+			desc:      "upgrade rejected",
+			status:    http.StatusBadRequest,
+			expectErr: true,
+		},
+		{
+			desc:      "key not accepted",
+			accept:    func(key string) string { return "invalid" },
+			status:    http.StatusSwitchingProtocols,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/ws" || req.Header.Get("Upgrade") != "websocket" || req.Header.Get("Sec-WebSocket-Version") != "13" {
+					rw.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				if test.status != http.StatusSwitchingProtocols {
+					rw.WriteHeader(test.status)
+					return
+				}
+
+				conn, buf, err := rw.(http.Hijacker).Hijack()
+				if err != nil {
+					return
+				}
+				defer func() { _ = conn.Close() }()
+
+				_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+					"Sec-WebSocket-Accept: " + test.accept(req.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+				_ = buf.Flush()
+			}))
+			t.Cleanup(server.Close)
+
+			backend, err := NewBackendConfig(Options{
+				Mode:    WSMode,
+				Path:    "/ws",
+				Timeout: healthCheckTimeout,
+			}, "backendName")
+			require.NoError(t, err)
+
+			err = checkHealth(context.Background(), testhelpers.MustParseURL(server.URL), backend)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -56,6 +56,13 @@ type Registry interface {
 	ServiceOpenConnsGauge() metrics.Gauge
 	ServiceRetriesCounter() metrics.Counter
 	ServiceServerUpGauge() metrics.Gauge
+	ServiceServerFailuresGauge() metrics.Gauge
+	ServiceHealthyRatioGauge() metrics.Gauge
+	ServiceDisabledServersGauge() metrics.Gauge
+	ServiceServerCertLifetimeGauge() metrics.Gauge
+	ServiceHealthCheckRequestsCounter() metrics.Counter
+	ServiceHealthCheckDurationHistogram() metrics.Histogram
+	ServiceHealthCheckCycleDurationHistogram() metrics.Histogram
 	ServiceReqsBytesCounter() metrics.Counter
 	ServiceRespsBytesCounter() metrics.Counter
 }
@@ -93,6 +100,13 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var serviceOpenConnsGauge []metrics.Gauge
 	var serviceRetriesCounter []metrics.Counter
 	var serviceServerUpGauge []metrics.Gauge
+	var serviceServerFailuresGauge []metrics.Gauge
+	var serviceHealthyRatioGauge []metrics.Gauge
+	var serviceDisabledServersGauge []metrics.Gauge
+	var serviceServerCertLifetimeGauge []metrics.Gauge
+	var serviceHealthCheckRequestsCounter []metrics.Counter
+	var serviceHealthCheckDurationHistogram []metrics.Histogram
+	var serviceHealthCheckCycleDurationHistogram []metrics.Histogram
 	var serviceReqsBytesCounter []metrics.Counter
 	var serviceRespsBytesCounter []metrics.Counter
 
@@ -166,6 +180,27 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceServerUpGauge() != nil {
 			serviceServerUpGauge = append(serviceServerUpGauge, r.ServiceServerUpGauge())
 		}
+		if r.ServiceServerFailuresGauge() != nil {
+			serviceServerFailuresGauge = append(serviceServerFailuresGauge, r.ServiceServerFailuresGauge())
+		}
+		if r.ServiceHealthyRatioGauge() != nil {
+			serviceHealthyRatioGauge = append(serviceHealthyRatioGauge, r.ServiceHealthyRatioGauge())
+		}
+		if r.ServiceDisabledServersGauge() != nil {
+			serviceDisabledServersGauge = append(serviceDisabledServersGauge, r.ServiceDisabledServersGauge())
+		}
+		if r.ServiceServerCertLifetimeGauge() != nil {
+			serviceServerCertLifetimeGauge = append(serviceServerCertLifetimeGauge, r.ServiceServerCertLifetimeGauge())
+		}
+		if r.ServiceHealthCheckRequestsCounter() != nil {
+			serviceHealthCheckRequestsCounter = append(serviceHealthCheckRequestsCounter, r.ServiceHealthCheckRequestsCounter())
+		}
+		if r.ServiceHealthCheckDurationHistogram() != nil {
+			serviceHealthCheckDurationHistogram = append(serviceHealthCheckDurationHistogram, r.ServiceHealthCheckDurationHistogram())
+		}
+		if r.ServiceHealthCheckCycleDurationHistogram() != nil {
+			serviceHealthCheckCycleDurationHistogram = append(serviceHealthCheckCycleDurationHistogram, r.ServiceHealthCheckCycleDurationHistogram())
+		}
 		if r.ServiceReqsBytesCounter() != nil {
 			serviceReqsBytesCounter = append(serviceReqsBytesCounter, r.ServiceReqsBytesCounter())
 		}
@@ -175,66 +210,80 @@ func NewMultiRegistry(registries []Registry) Registry {
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		routerEnabled:                  len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0 || len(routerOpenConnsGauge) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
-		entryPointReqsCounter:          multi.NewCounter(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: MultiHistogram(entryPointReqDurationHistogram),
-		entryPointOpenConnsGauge:       multi.NewGauge(entryPointOpenConnsGauge...),
-		entryPointReqsBytesCounter:     multi.NewCounter(entryPointReqsBytesCounter...),
-		entryPointRespsBytesCounter:    multi.NewCounter(entryPointRespsBytesCounter...),
-		routerReqsCounter:              multi.NewCounter(routerReqsCounter...),
-		routerReqsTLSCounter:           multi.NewCounter(routerReqsTLSCounter...),
-		routerReqDurationHistogram:     MultiHistogram(routerReqDurationHistogram),
-		routerOpenConnsGauge:           multi.NewGauge(routerOpenConnsGauge...),
-		routerReqsBytesCounter:         multi.NewCounter(routerReqsBytesCounter...),
-		routerRespsBytesCounter:        multi.NewCounter(routerRespsBytesCounter...),
-		serviceReqsCounter:             multi.NewCounter(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    MultiHistogram(serviceReqDurationHistogram),
-		serviceOpenConnsGauge:          multi.NewGauge(serviceOpenConnsGauge...),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
-		serviceReqsBytesCounter:        multi.NewCounter(serviceReqsBytesCounter...),
-		serviceRespsBytesCounter:       multi.NewCounter(serviceRespsBytesCounter...),
+		epEnabled:                                len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
+		svcEnabled:                               len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		routerEnabled:                            len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0 || len(routerOpenConnsGauge) > 0,
+		configReloadsCounter:                     multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:              multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:             multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:             multi.NewGauge(lastConfigReloadFailureGauge...),
+		tlsCertsNotAfterTimestampGauge:           multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		entryPointReqsCounter:                    multi.NewCounter(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:                 multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:           MultiHistogram(entryPointReqDurationHistogram),
+		entryPointOpenConnsGauge:                 multi.NewGauge(entryPointOpenConnsGauge...),
+		entryPointReqsBytesCounter:               multi.NewCounter(entryPointReqsBytesCounter...),
+		entryPointRespsBytesCounter:              multi.NewCounter(entryPointRespsBytesCounter...),
+		routerReqsCounter:                        multi.NewCounter(routerReqsCounter...),
+		routerReqsTLSCounter:                     multi.NewCounter(routerReqsTLSCounter...),
+		routerReqDurationHistogram:               MultiHistogram(routerReqDurationHistogram),
+		routerOpenConnsGauge:                     multi.NewGauge(routerOpenConnsGauge...),
+		routerReqsBytesCounter:                   multi.NewCounter(routerReqsBytesCounter...),
+		routerRespsBytesCounter:                  multi.NewCounter(routerRespsBytesCounter...),
+		serviceReqsCounter:                       multi.NewCounter(serviceReqsCounter...),
+		serviceReqsTLSCounter:                    multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:              MultiHistogram(serviceReqDurationHistogram),
+		serviceOpenConnsGauge:                    multi.NewGauge(serviceOpenConnsGauge...),
+		serviceRetriesCounter:                    multi.NewCounter(serviceRetriesCounter...),
+		serviceServerUpGauge:                     multi.NewGauge(serviceServerUpGauge...),
+		serviceServerFailuresGauge:               multi.NewGauge(serviceServerFailuresGauge...),
+		serviceHealthyRatioGauge:                 multi.NewGauge(serviceHealthyRatioGauge...),
+		serviceDisabledServersGauge:              multi.NewGauge(serviceDisabledServersGauge...),
+		serviceServerCertLifetimeGauge:           multi.NewGauge(serviceServerCertLifetimeGauge...),
+		serviceHealthCheckRequestsCounter:        multi.NewCounter(serviceHealthCheckRequestsCounter...),
+		serviceHealthCheckDurationHistogram:      multi.NewHistogram(serviceHealthCheckDurationHistogram...),
+		serviceHealthCheckCycleDurationHistogram: multi.NewHistogram(serviceHealthCheckCycleDurationHistogram...),
+		serviceReqsBytesCounter:                  multi.NewCounter(serviceReqsBytesCounter...),
+		serviceRespsBytesCounter:                 multi.NewCounter(serviceRespsBytesCounter...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	routerEnabled                  bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	tlsCertsNotAfterTimestampGauge metrics.Gauge
-	entryPointReqsCounter          metrics.Counter
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointOpenConnsGauge       metrics.Gauge
-	entryPointReqsBytesCounter     metrics.Counter
-	entryPointRespsBytesCounter    metrics.Counter
-	routerReqsCounter              metrics.Counter
-	routerReqsTLSCounter           metrics.Counter
-	routerReqDurationHistogram     ScalableHistogram
-	routerOpenConnsGauge           metrics.Gauge
-	routerReqsBytesCounter         metrics.Counter
-	routerRespsBytesCounter        metrics.Counter
-	serviceReqsCounter             metrics.Counter
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceOpenConnsGauge          metrics.Gauge
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
-	serviceReqsBytesCounter        metrics.Counter
-	serviceRespsBytesCounter       metrics.Counter
+	epEnabled                                bool
+	routerEnabled                            bool
+	svcEnabled                               bool
+	configReloadsCounter                     metrics.Counter
+	configReloadsFailureCounter              metrics.Counter
+	lastConfigReloadSuccessGauge             metrics.Gauge
+	lastConfigReloadFailureGauge             metrics.Gauge
+	tlsCertsNotAfterTimestampGauge           metrics.Gauge
+	entryPointReqsCounter                    metrics.Counter
+	entryPointReqsTLSCounter                 metrics.Counter
+	entryPointReqDurationHistogram           ScalableHistogram
+	entryPointOpenConnsGauge                 metrics.Gauge
+	entryPointReqsBytesCounter               metrics.Counter
+	entryPointRespsBytesCounter              metrics.Counter
+	routerReqsCounter                        metrics.Counter
+	routerReqsTLSCounter                     metrics.Counter
+	routerReqDurationHistogram               ScalableHistogram
+	routerOpenConnsGauge                     metrics.Gauge
+	routerReqsBytesCounter                   metrics.Counter
+	routerRespsBytesCounter                  metrics.Counter
+	serviceReqsCounter                       metrics.Counter
+	serviceReqsTLSCounter                    metrics.Counter
+	serviceReqDurationHistogram              ScalableHistogram
+	serviceOpenConnsGauge                    metrics.Gauge
+	serviceRetriesCounter                    metrics.Counter
+	serviceServerUpGauge                     metrics.Gauge
+	serviceServerFailuresGauge               metrics.Gauge
+	serviceHealthyRatioGauge                 metrics.Gauge
+	serviceDisabledServersGauge              metrics.Gauge
+	serviceServerCertLifetimeGauge           metrics.Gauge
+	serviceHealthCheckRequestsCounter        metrics.Counter
+	serviceHealthCheckDurationHistogram      metrics.Histogram
+	serviceHealthCheckCycleDurationHistogram metrics.Histogram
+	serviceReqsBytesCounter                  metrics.Counter
+	serviceRespsBytesCounter                 metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -341,6 +390,34 @@ func (r *standardRegistry) ServiceServerUpGauge() metrics.Gauge {
 	return r.serviceServerUpGauge
 }
 
+func (r *standardRegistry) ServiceServerFailuresGauge() metrics.Gauge {
+	return r.serviceServerFailuresGauge
+}
+
+func (r *standardRegistry) ServiceHealthyRatioGauge() metrics.Gauge {
+	return r.serviceHealthyRatioGauge
+}
+
+func (r *standardRegistry) ServiceDisabledServersGauge() metrics.Gauge {
+	return r.serviceDisabledServersGauge
+}
+
+func (r *standardRegistry) ServiceServerCertLifetimeGauge() metrics.Gauge {
+	return r.serviceServerCertLifetimeGauge
+}
+
+func (r *standardRegistry) ServiceHealthCheckRequestsCounter() metrics.Counter {
+	return r.serviceHealthCheckRequestsCounter
+}
+
+func (r *standardRegistry) ServiceHealthCheckDurationHistogram() metrics.Histogram {
+	return r.serviceHealthCheckDurationHistogram
+}
+
+func (r *standardRegistry) ServiceHealthCheckCycleDurationHistogram() metrics.Histogram {
+	return r.serviceHealthCheckCycleDurationHistogram
+}
+
 func (r *standardRegistry) ServiceReqsBytesCounter() metrics.Counter {
 	return r.serviceReqsBytesCounter
 }
@@ -50,15 +50,22 @@ const (
 	routerRespsBytesTotalName = metricRouterPrefix + "responses_bytes_total"
 
 	// service level.
-	metricServicePrefix        = MetricNamePrefix + "service_"
-	serviceReqsTotalName       = metricServicePrefix + "requests_total"
-	serviceReqsTLSTotalName    = metricServicePrefix + "requests_tls_total"
-	serviceReqDurationName     = metricServicePrefix + "request_duration_seconds"
-	serviceOpenConnsName       = metricServicePrefix + "open_connections"
-	serviceRetriesTotalName    = metricServicePrefix + "retries_total"
-	serviceServerUpName        = metricServicePrefix + "server_up"
-	serviceReqsBytesTotalName  = metricServicePrefix + "requests_bytes_total"
-	serviceRespsBytesTotalName = metricServicePrefix + "responses_bytes_total"
+	metricServicePrefix                 = MetricNamePrefix + "service_"
+	serviceReqsTotalName                = metricServicePrefix + "requests_total"
+	serviceReqsTLSTotalName             = metricServicePrefix + "requests_tls_total"
+	serviceReqDurationName              = metricServicePrefix + "request_duration_seconds"
+	serviceOpenConnsName                = metricServicePrefix + "open_connections"
+	serviceRetriesTotalName             = metricServicePrefix + "retries_total"
+	serviceServerUpName                 = metricServicePrefix + "server_up"
+	serviceServerFailuresName           = metricServicePrefix + "server_consecutive_failures"
+	serviceHealthyRatioName             = metricServicePrefix + "healthy_ratio"
+	serviceDisabledServersName          = metricServicePrefix + "disabled_servers"
+	serviceServerCertLifetimeName       = metricServicePrefix + "server_cert_lifetime_seconds"
+	serviceHealthCheckRequestsName      = metricServicePrefix + "health_check_requests_total"
+	serviceHealthCheckDurationName      = metricServicePrefix + "health_check_duration_seconds"
+	serviceHealthCheckCycleDurationName = metricServicePrefix + "health_check_cycle_duration_seconds"
+	serviceReqsBytesTotalName           = metricServicePrefix + "requests_bytes_total"
+	serviceRespsBytesTotalName          = metricServicePrefix + "responses_bytes_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -267,6 +274,36 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			Name: serviceServerUpName,
 			Help: "service server is up, described by gauge value of 0 or 1.",
 		}, []string{"service", "url"})
+		serviceServerFailures := newGaugeFrom(stdprometheus.GaugeOpts{
+			Name: serviceServerFailuresName,
+			Help: "How many health checks of a service server failed in a row.",
+		}, []string{"service", "url"})
+		serviceHealthyRatio := newGaugeFrom(stdprometheus.GaugeOpts{
+			Name: serviceHealthyRatioName,
+			Help: "Fraction of the servers of a service which are up, between 0 and 1.",
+		}, []string{"service"})
+		serviceDisabledServers := newGaugeFrom(stdprometheus.GaugeOpts{
+			Name: serviceDisabledServersName,
+			Help: "How many servers of a service are disabled by the health check.",
+		}, []string{"service"})
+		serviceServerCertLifetime := newGaugeFrom(stdprometheus.GaugeOpts{
+			Name: serviceServerCertLifetimeName,
+			Help: "Remaining lifetime, in seconds, of the certificate presented by a service server to the health check.",
+		}, []string{"service", "url"})
+		serviceHealthCheckRequests := newCounterFrom(stdprometheus.CounterOpts{
+			Name: serviceHealthCheckRequestsName,
+			Help: "How many health checks of the service servers were performed, partitioned by result and failure reason.",
+		}, []string{"service", "url", "result", "reason"})
+		serviceHealthCheckDurations := newHistogramFrom(stdprometheus.HistogramOpts{
+			Name:    serviceHealthCheckDurationName,
+			Help:    "How long it took to check the health of the servers of a service.",
+			Buckets: buckets,
+		}, []string{"service"})
+		serviceHealthCheckCycleDurations := newHistogramFrom(stdprometheus.HistogramOpts{
+			Name:    serviceHealthCheckCycleDurationName,
+			Help:    "How long it took to check the health of all the servers of a service, in a single round of checks.",
+			Buckets: buckets,
+		}, []string{"service"})
 		serviceReqsBytesTotal := newCounterFrom(stdprometheus.CounterOpts{
 			Name: serviceReqsBytesTotalName,
 			Help: "The total size of requests in bytes received by a service, partitioned by status code, protocol, and method.",
@@ -283,6 +320,13 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			serviceOpenConns.gv,
 			serviceRetries.cv,
 			serviceServerUp.gv,
+			serviceServerFailures.gv,
+			serviceHealthyRatio.gv,
+			serviceDisabledServers.gv,
+			serviceServerCertLifetime.gv,
+			serviceHealthCheckRequests.cv,
+			serviceHealthCheckDurations.hv,
+			serviceHealthCheckCycleDurations.hv,
 			serviceReqsBytesTotal.cv,
 			serviceRespsBytesTotal.cv,
 		)
@@ -293,6 +337,13 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		reg.serviceOpenConnsGauge = serviceOpenConns
 		reg.serviceRetriesCounter = serviceRetries
 		reg.serviceServerUpGauge = serviceServerUp
+		reg.serviceServerFailuresGauge = serviceServerFailures
+		reg.serviceHealthyRatioGauge = serviceHealthyRatio
+		reg.serviceDisabledServersGauge = serviceDisabledServers
+		reg.serviceServerCertLifetimeGauge = serviceServerCertLifetime
+		reg.serviceHealthCheckRequestsCounter = serviceHealthCheckRequests
+		reg.serviceHealthCheckDurationHistogram = serviceHealthCheckDurations
+		reg.serviceHealthCheckCycleDurationHistogram = serviceHealthCheckCycleDurations
 		reg.serviceReqsBytesCounter = serviceReqsBytesTotal
 		reg.serviceRespsBytesCounter = serviceRespsBytesTotal
 	}
@@ -179,6 +179,34 @@ func TestPrometheus(t *testing.T) {
 		ServiceServerUpGauge().
 		With("service", "service1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		ServiceServerFailuresGauge().
+		With("service", "service1", "url", "http://127.0.0.10:80").
+		Set(2)
+	prometheusRegistry.
+		ServiceHealthyRatioGauge().
+		With("service", "service1").
+		Set(1)
+	prometheusRegistry.
+		ServiceDisabledServersGauge().
+		With("service", "service1").
+		Set(1)
+	prometheusRegistry.
+		ServiceServerCertLifetimeGauge().
+		With("service", "service1", "url", "http://127.0.0.10:80").
+		Set(3600)
+	prometheusRegistry.
+		ServiceHealthCheckRequestsCounter().
+		With("service", "service1", "url", "http://127.0.0.10:80", "result", "failure", "reason", "timeout").
+		Add(1)
+	prometheusRegistry.
+		ServiceHealthCheckDurationHistogram().
+		With("service", "service1").
+		Observe(1)
+	prometheusRegistry.
+		ServiceHealthCheckCycleDurationHistogram().
+		With("service", "service1").
+		Observe(1)
 	prometheusRegistry.
 		ServiceRespsBytesCounter().
 		With("service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
@@ -388,6 +416,60 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, serviceServerUpName, 1),
 		},
+		{
+			name: serviceServerFailuresName,
+			labels: map[string]string{
+				"service": "service1",
+				"url":     "http://127.0.0.10:80",
+			},
+			assert: buildGaugeAssert(t, serviceServerFailuresName, 2),
+		},
+		{
+			name: serviceHealthyRatioName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildGaugeAssert(t, serviceHealthyRatioName, 1),
+		},
+		{
+			name: serviceDisabledServersName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildGaugeAssert(t, serviceDisabledServersName, 1),
+		},
+		{
+			name: serviceServerCertLifetimeName,
+			labels: map[string]string{
+				"service": "service1",
+				"url":     "http://127.0.0.10:80",
+			},
+			assert: buildGaugeAssert(t, serviceServerCertLifetimeName, 3600),
+		},
+		{
+			name: serviceHealthCheckRequestsName,
+			labels: map[string]string{
+				"service": "service1",
+				"url":     "http://127.0.0.10:80",
+				"result":  "failure",
+				"reason":  "timeout",
+			},
+			assert: buildCounterAssert(t, serviceHealthCheckRequestsName, 1),
+		},
+		{
+			name: serviceHealthCheckDurationName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildHistogramAssert(t, serviceHealthCheckDurationName, 1),
+		},
+		{
+			name: serviceHealthCheckCycleDurationName,
+			labels: map[string]string{
+				"service": "service1",
+			},
+			assert: buildHistogramAssert(t, serviceHealthCheckCycleDurationName, 1),
+		},
 		{
 			name: serviceReqsBytesTotalName,
 			labels: map[string]string{
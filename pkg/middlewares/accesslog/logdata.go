@@ -129,6 +129,8 @@ type LogData struct {
 	Request            request
 	OriginResponse     http.Header
 	DownstreamResponse downstreamResponse
+	// TraceSampled is whether the request is part of a sampled trace.
+	TraceSampled bool
 }
 
 type downstreamResponse struct {
@@ -506,6 +506,15 @@ func TestNewLogHandlerOutputStdout(t *testing.T) {
 			},
 			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
 		},
+		{
+			desc: "sampled traces only without tracing",
+			config: &types.AccessLog{
+				FilePath:          "",
+				Format:            CommonFormat,
+				SampledTracesOnly: true,
+			},
+			expectedLog: ``,
+		},
 		{
 			desc: "default config with empty filters",
 			config: &types.AccessLog{
@@ -0,0 +1,340 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const (
+	externalOPATypeName = "ExternalOPA"
+
+	// defaultOPATimeout is the timeout of the requests to the OPA server when none is configured.
+	defaultOPATimeout = 30 * time.Second
+)
+
+// policyCheckPeriod is the period of the checks of the policy file for changes.
+var policyCheckPeriod = time.Second
+
+// externalOPA is a client of an external OPA server, which evaluates the policy decisions.
+type externalOPA struct {
+	next         http.Handler
+	name         string
+	client       *http.Client
+	decisionURL  string
+	inputHeaders []string
+	reasonHeader string
+	// sendClaims is whether the claims of the bearer token are sent, i.e. whether the Authorization header is an input header.
+	sendClaims bool
+}
+
+// policyWatcher pushes the policy file of an ExternalOPA middleware to the OPA server.
+type policyWatcher struct {
+	client     *http.Client
+	policyFile string
+	policyURL  string
+	// policyModTime is the modification time of the last pushed policy file.
+	policyModTime time.Time
+}
+
+// opaInput is the input document of a policy evaluation.
+type opaInput struct {
+	Method  string      `json:"method"`
+	Host    string      `json:"host"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query"`
+	Headers http.Header `json:"headers"`
+	// UnverifiedClaims are the claims of the JWT bearer token of the request, whose signature is not verified.
+	UnverifiedClaims map[string]interface{} `json:"unverifiedClaims,omitempty"`
+}
+
+// opaDecision is the result of a policy evaluation,
+// which is either a boolean, or an object with an allow and a reason field.
+type opaDecision struct {
+	Allow  bool
+	Reason string
+}
+
+func (d *opaDecision) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.Allow); err == nil {
+		return nil
+	}
+
+	var decision struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return fmt.Errorf("unsupported decision %s: %w", string(data), err)
+	}
+
+	d.Allow = decision.Allow
+	d.Reason = decision.Reason
+	return nil
+}
+
+// NewExternalOPA creates an ExternalOPA authorization middleware.
+// Its policy file, if any, is pushed by WatchExternalOPAPolicy, once per middleware definition.
+func NewExternalOPA(ctx context.Context, next http.Handler, config dynamic.ExternalOPA, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, externalOPATypeName))
+	logger.Debug("Creating middleware")
+
+	if config.Address == "" {
+		return nil, errors.New("an OPA server address must be provided")
+	}
+	if config.Decision == "" {
+		return nil, errors.New("a decision path must be provided")
+	}
+
+	o := &externalOPA{
+		next:         next,
+		name:         name,
+		client:       newOPAClient(config),
+		decisionURL:  opaAddress(config) + "/v1/data/" + strings.Trim(config.Decision, "/"),
+		inputHeaders: config.InputHeaders,
+		reasonHeader: config.ReasonHeader,
+	}
+
+	for _, header := range config.InputHeaders {
+		if http.CanonicalHeaderKey(header) == "Authorization" {
+			o.sendClaims = true
+		}
+	}
+
+	return o, nil
+}
+
+// WatchExternalOPAPolicy pushes the policy file of the given ExternalOPA middleware, if any, to the OPA server,
+// and pushes it again whenever it changes, until the given context is done, i.e. until the middleware is replaced by a new configuration.
+// It is meant to be called once per middleware definition, whatever the number of routers using it.
+func WatchExternalOPAPolicy(ctx context.Context, config dynamic.ExternalOPA, name string) {
+	if config.Address == "" || config.PolicyFile == "" {
+		return
+	}
+
+	w := &policyWatcher{
+		client:     newOPAClient(config),
+		policyFile: config.PolicyFile,
+		policyURL:  opaAddress(config) + "/v1/policies/" + url.PathEscape(name),
+	}
+
+	// The policy is pushed in the background, so that an unreachable OPA server does not delay the creation of the middleware.
+	safe.Go(func() { w.watch(middlewares.GetLoggerCtx(ctx, name, externalOPATypeName)) })
+}
+
+// opaAddress returns the address of the OPA server of the given configuration, without trailing slash.
+func opaAddress(config dynamic.ExternalOPA) string {
+	return strings.TrimSuffix(config.Address, "/")
+}
+
+// newOPAClient returns the client of the requests to the OPA server of the given configuration.
+func newOPAClient(config dynamic.ExternalOPA) *http.Client {
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = defaultOPATimeout
+	}
+
+	return &http.Client{
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: timeout,
+	}
+}
+
+func (o *externalOPA) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, ext.SpanKindRPCClientEnum
+}
+
+func (o *externalOPA) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), o.name, externalOPATypeName))
+
+	decision, err := o.evaluate(req)
+	if err != nil {
+		logMessage := fmt.Sprintf("Error evaluating the policy decision %s. Cause: %s", o.decisionURL, err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !decision.Allow {
+		logger.Debugf("Request denied by the policy decision %s: %s", o.decisionURL, decision.Reason)
+		tracing.SetErrorWithEvent(req, "Request denied by the policy")
+
+		if o.reasonHeader != "" && decision.Reason != "" {
+			rw.Header().Set(o.reasonHeader, decision.Reason)
+		}
+
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	o.next.ServeHTTP(rw, req)
+}
+
+func (o *externalOPA) evaluate(req *http.Request) (*opaDecision, error) {
+	input := opaInput{
+		Method:  req.Method,
+		Host:    req.Host,
+		Path:    req.URL.Path,
+		Query:   req.URL.RawQuery,
+		Headers: filterInputHeaders(req.Header, o.inputHeaders),
+	}
+
+	// The claims are part of the Authorization header, which is only sent when it is an input header.
+	if o.sendClaims {
+		input.UnverifiedClaims = bearerClaims(req.Header.Get("Authorization"))
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	opaReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, o.decisionURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	opaReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(opaReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status code %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result *opaDecision `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	// An undefined decision denies the request.
+	if result.Result == nil {
+		return &opaDecision{Reason: "undefined decision"}, nil
+	}
+
+	return result.Result, nil
+}
+
+// watch pushes the policy file to the OPA server, and pushes it again whenever it changes, until the given context is done.
+// A failed push is retried on the next check of the file, e.g. when the OPA server is not started yet.
+func (w *policyWatcher) watch(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	ticker := time.NewTicker(policyCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := w.push(ctx); err != nil && ctx.Err() == nil {
+			logger.Errorf("Unable to push the policy %s: %v", w.policyFile, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// push pushes the policy file to the OPA server if it changed since the last push.
+func (w *policyWatcher) push(ctx context.Context) error {
+	info, err := os.Stat(w.policyFile)
+	if err != nil {
+		return err
+	}
+
+	if info.ModTime().Equal(w.policyModTime) {
+		return nil
+	}
+
+	policy, err := os.ReadFile(w.policyFile)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, w.policyURL, bytes.NewReader(policy))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("received status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	w.policyModTime = info.ModTime()
+
+	log.FromContext(ctx).Debugf("Policy %s pushed to %s", w.policyFile, w.policyURL)
+
+	return nil
+}
+
+// filterInputHeaders returns the given input headers among the request headers, so that only those are sent to the OPA server.
+func filterInputHeaders(headers http.Header, inputHeaders []string) http.Header {
+	filtered := http.Header{}
+	for _, name := range inputHeaders {
+		if values := headers.Values(name); len(values) > 0 {
+			filtered[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+
+	return filtered
+}
+
+// bearerClaims returns the claims of the JWT bearer token of the given Authorization header value.
+// The token signature is not verified, hence the claims are sent as unverified claims.
+func bearerClaims(authorization string) map[string]interface{} {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == authorization {
+		return nil
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+
+	return claims
+}
@@ -3,6 +3,7 @@ package circuitbreaker
 import (
 	"context"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentracing/opentracing-go/ext"
@@ -15,28 +16,59 @@ import (
 
 const typeName = "CircuitBreaker"
 
+const serverUp = "UP"
+
+// ServiceStatus gives the status of the servers of a service, as implemented by runtime.ServiceInfo.
+type ServiceStatus interface {
+	GetAllStatus() map[string]string
+}
+
 type circuitBreaker struct {
 	circuitBreaker *cbreaker.CircuitBreaker
 	name           string
+	fallback       http.Handler
+
+	// serviceStatus, if not nil, must have a healthy server for the circuit breaker to try to recover.
+	serviceStatus ServiceStatus
+	// tripped is set to 1 when the circuit breaker serves its fallback,
+	// and back to 0 when it lets a request through to the service.
+	tripped int32
 }
 
 // New creates a new circuit breaker middleware.
-func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, name string) (http.Handler, error) {
+// If serviceStatus is not nil, a tripped circuit breaker does not try to recover until the service has a healthy server.
+func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, serviceStatus ServiceStatus, name string) (http.Handler, error) {
 	expression := confCircuitBreaker.Expression
 
 	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
 	logger.Debug("Creating middleware")
 	logger.Debugf("Setting up with expression: %s", expression)
 
-	cbOpts := []cbreaker.CircuitBreakerOption{
-		cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	cb := &circuitBreaker{
+		name:          name,
+		serviceStatus: serviceStatus,
+		fallback: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 			tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
 			rw.WriteHeader(http.StatusServiceUnavailable)
 
 			if _, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable))); err != nil {
 				log.FromContext(req.Context()).Error(err)
 			}
-		})),
+		}),
+	}
+
+	fallback := cb.fallback
+	if serviceStatus != nil {
+		logger.Debugf("Waiting for a healthy server of %s before recovering", confCircuitBreaker.HealthCheckService)
+
+		// The tripped state is tracked from the handlers oxy calls, rather than from its state change callbacks,
+		// which run asynchronously.
+		fallback = http.HandlerFunc(cb.serveFallback)
+		next = cb.wrapNext(next)
+	}
+
+	cbOpts := []cbreaker.CircuitBreakerOption{
+		cbreaker.Fallback(fallback),
 	}
 
 	if confCircuitBreaker.CheckPeriod > 0 {
@@ -55,10 +87,9 @@ func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.Circ
 	if err != nil {
 		return nil, err
 	}
-	return &circuitBreaker{
-		circuitBreaker: oxyCircuitBreaker,
-		name:           name,
-	}, nil
+	cb.circuitBreaker = oxyCircuitBreaker
+
+	return cb, nil
 }
 
 func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -66,5 +97,36 @@ func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (c *circuitBreaker) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if atomic.LoadInt32(&c.tripped) == 1 && !c.healthy() {
+		c.fallback.ServeHTTP(rw, req)
+		return
+	}
+
 	c.circuitBreaker.ServeHTTP(rw, req)
 }
+
+// healthy returns whether the service has at least one healthy server.
+func (c *circuitBreaker) healthy() bool {
+	for _, status := range c.serviceStatus.GetAllStatus() {
+		if status == serverUp {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveFallback is the fallback handler called by oxy while the circuit breaker is open,
+// i.e. tripped or not letting the request through while recovering.
+func (c *circuitBreaker) serveFallback(rw http.ResponseWriter, req *http.Request) {
+	atomic.StoreInt32(&c.tripped, 1)
+	c.fallback.ServeHTTP(rw, req)
+}
+
+// wrapNext wraps the handler called by oxy when it lets a request through to the service.
+func (c *circuitBreaker) wrapNext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.StoreInt32(&c.tripped, 0)
+		next.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,104 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+type serviceStatus struct {
+	mu     sync.Mutex
+	status string
+}
+
+func (s *serviceStatus) set(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = status
+}
+
+func (s *serviceStatus) GetAllStatus() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return map[string]string{"http://127.0.0.1": s.status}
+}
+
+func TestCircuitBreaker_healthCheckService(t *testing.T) {
+	var calls int32
+	var failing int32 = 1
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	status := &serviceStatus{status: "UP"}
+
+	handler, err := New(context.Background(), next, dynamic.CircuitBreaker{
+		Expression:         "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+		CheckPeriod:        ptypes.Duration(time.Millisecond),
+		FallbackDuration:   ptypes.Duration(10 * time.Millisecond),
+		RecoveryDuration:   ptypes.Duration(10 * time.Millisecond),
+		HealthCheckService: "foo",
+	}, status, "cb")
+	require.NoError(t, err)
+
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+		return recorder.Code
+	}
+
+	tripped := func() bool {
+		return atomic.LoadInt32(&handler.(*circuitBreaker).tripped) == 1
+	}
+
+	// Trip the circuit breaker while the service becomes unhealthy.
+	require.Eventually(t, func() bool {
+		return serve() == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+	assert.True(t, tripped())
+
+	status.set("DOWN")
+	atomic.StoreInt32(&failing, 0)
+
+	// The fallback duration expires, but the circuit breaker does not try to recover.
+	time.Sleep(20 * time.Millisecond)
+	callsBefore := atomic.LoadInt32(&calls)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusServiceUnavailable, serve())
+	}
+	assert.Equal(t, callsBefore, atomic.LoadInt32(&calls))
+
+	// Once the service is healthy, the circuit breaker recovers.
+	status.set("UP")
+	assert.Eventually(t, func() bool {
+		return serve() == http.StatusOK
+	}, time.Second, time.Millisecond)
+	assert.False(t, tripped())
+}
+
+func TestCircuitBreaker_noHealthCheckService(t *testing.T) {
+	handler, err := New(context.Background(), http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), dynamic.CircuitBreaker{
+		Expression: "NetworkErrorRatio() > 0.5",
+	}, nil, "cb")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
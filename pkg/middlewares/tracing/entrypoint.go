@@ -9,6 +9,7 @@ import (
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
 	"github.com/traefik/traefik/v2/pkg/tracing"
 )
 
@@ -46,6 +47,10 @@ func (e *entryPointMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 	ext.Component.Set(span, e.ServiceName)
 	tracing.LogRequest(span, req)
 
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.TraceSampled = tracing.IsSampled(span)
+	}
+
 	req = req.WithContext(tracing.WithTracing(req.Context(), e.Tracing))
 
 	recorder := newStatusCodeRecoder(rw, http.StatusOK)
@@ -4,12 +4,23 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/containous/alice"
+	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	zipkinot "github.com/openzipkin-contrib/zipkin-go-opentracing"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/reporter/recorder"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/middlewares/capture"
 	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/traefik/traefik/v2/pkg/types"
+	"github.com/uber/jaeger-client-go"
 )
 
 func TestEntryPointMiddleware(t *testing.T) {
@@ -84,3 +95,91 @@ func TestEntryPointMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestEntryPointMiddleware_sampledAccessLogs(t *testing.T) {
+	newZipkinTracer := func(sampler zipkin.Sampler) opentracing.Tracer {
+		tracer, err := zipkin.NewTracer(recorder.NewReporter(), zipkin.WithSampler(sampler))
+		require.NoError(t, err)
+
+		return zipkinot.Wrap(tracer)
+	}
+
+	testCases := []struct {
+		desc        string
+		tracer      opentracing.Tracer
+		headers     map[string]string
+		expectedLog bool
+	}{
+		{
+			desc:        "jaeger sampled trace",
+			tracer:      newJaegerTracer(t, true),
+			expectedLog: true,
+		},
+		{
+			desc:   "jaeger unsampled trace",
+			tracer: newJaegerTracer(t, false),
+		},
+		{
+			desc:        "jaeger sampled parent span context",
+			tracer:      newJaegerTracer(t, false),
+			headers:     map[string]string{jaeger.TraceContextHeaderName: "1:2:0:1"},
+			expectedLog: true,
+		},
+		{
+			desc:    "jaeger unsampled parent span context",
+			tracer:  newJaegerTracer(t, true),
+			headers: map[string]string{jaeger.TraceContextHeaderName: "1:2:0:0"},
+		},
+		{
+			desc:        "zipkin sampled trace",
+			tracer:      newZipkinTracer(zipkin.AlwaysSample),
+			expectedLog: true,
+		},
+		{
+			desc:   "zipkin unsampled trace",
+			tracer: newZipkinTracer(zipkin.NeverSample),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			newTracing, err := tracing.NewTracing("", 0, &trackingBackenMock{tracer: test.tracer})
+			require.NoError(t, err)
+
+			logFilePath := filepath.Join(t.TempDir(), "access.log")
+			logger, err := accesslog.NewHandler(&types.AccessLog{FilePath: logFilePath, Format: accesslog.CommonFormat, SampledTracesOnly: true})
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, logger.Close()) })
+
+			next := NewEntryPoint(context.Background(), newTracing, "test", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			handler, err := alice.New(capture.Wrap, accesslog.WrapHandler(logger)).Then(next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			written, err := os.ReadFile(logFilePath)
+			require.NoError(t, err)
+
+			if test.expectedLog {
+				assert.NotEmpty(t, written)
+			} else {
+				assert.Empty(t, written)
+			}
+		})
+	}
+}
+
+func newJaegerTracer(t *testing.T, sampled bool) opentracing.Tracer {
+	t.Helper()
+
+	tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(sampled), jaeger.NewNullReporter())
+	t.Cleanup(func() { _ = closer.Close() })
+
+	return tracer
+}
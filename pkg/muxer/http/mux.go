@@ -27,6 +27,7 @@ var httpFuncs = map[string]func(*mux.Route, ...string) error{
 	"Headers":       headers,
 	"HeadersRegexp": headersRegexp,
 	"Query":         query,
+	"Protocol":      protocol,
 }
 
 // Muxer handles routing with rules.
@@ -245,6 +246,32 @@ func query(route *mux.Route, query ...string) error {
 	return route.GetError()
 }
 
+func protocol(route *mux.Route, protocols ...string) error {
+	type version struct{ major, minor int }
+
+	var versions []version
+	for _, proto := range protocols {
+		major, minor, ok := http.ParseHTTPVersion(strings.ToUpper(proto))
+		if !ok {
+			log.WithoutContext().Warnf("\"Protocol\" matcher: %q is not a valid protocol version, it never matches", proto)
+			continue
+		}
+
+		versions = append(versions, version{major: major, minor: minor})
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		for _, v := range versions {
+			if req.ProtoMajor == v.major && req.ProtoMinor == v.minor {
+				return true
+			}
+		}
+		return false
+	})
+
+	return nil
+}
+
 func addRuleOnRouter(router *mux.Router, rule *rules.Tree) error {
 	switch rule.Matcher {
 	case "and":
@@ -20,6 +20,7 @@ func Test_addRoute(t *testing.T) {
 		rule          string
 		headers       map[string]string
 		remoteAddr    string
+		proto         string
 		expected      map[string]int
 		expectedError bool
 	}{
@@ -636,6 +637,63 @@ func Test_addRoute(t *testing.T) {
 				"http://tchouk/toto": http.StatusOK,
 			},
 		},
+		{
+			desc:  "Matching HTTP/2.0 Protocol",
+			rule:  "Protocol(`HTTP/2.0`)",
+			proto: "HTTP/2.0",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusOK,
+			},
+		},
+		{
+			desc:  "Non matching HTTP/2.0 Protocol",
+			rule:  "Protocol(`HTTP/2.0`)",
+			proto: "HTTP/1.1",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusNotFound,
+			},
+		},
+		{
+			desc:  "Matching HTTP/1.1 Protocol",
+			rule:  "Protocol(`HTTP/1.1`)",
+			proto: "HTTP/1.1",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusOK,
+			},
+		},
+		{
+			desc:  "Matching Protocol among several Protocols",
+			rule:  "Protocol(`HTTP/1.0`, `HTTP/1.1`)",
+			proto: "HTTP/1.0",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusOK,
+			},
+		},
+		{
+			desc:  "Invalid Protocol never matching",
+			rule:  "Protocol(`h2`)",
+			proto: "HTTP/2.0",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusNotFound,
+			},
+		},
+		{
+			desc:  "Protocol AND Host",
+			rule:  "Protocol(`HTTP/2.0`) && Host(`tchouk`)",
+			proto: "HTTP/2.0",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusOK,
+				"http://pouet/toto":  http.StatusNotFound,
+			},
+		},
+		{
+			desc:  "Not Protocol",
+			rule:  "!Protocol(`HTTP/2.0`)",
+			proto: "HTTP/1.1",
+			expected: map[string]int{
+				"http://tchouk/toto": http.StatusOK,
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -666,6 +724,14 @@ func Test_addRoute(t *testing.T) {
 					// Useful for the ClientIP matcher
 					req.RemoteAddr = test.remoteAddr
 
+					// Useful for the Protocol matcher
+					if test.proto != "" {
+						var ok bool
+						req.ProtoMajor, req.ProtoMinor, ok = http.ParseHTTPVersion(test.proto)
+						require.True(t, ok)
+						req.Proto = test.proto
+					}
+
 					for key, value := range test.headers {
 						req.Header.Set(key, value)
 					}
@@ -220,6 +220,12 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
+		externalOPA, err := createExternalOPAMiddleware(middleware.Spec.ExternalOPA)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading external OPA middleware: %v", err)
+			continue
+		}
+
 		errorPage, errorPageService, err := p.createErrorPageMiddleware(client, middleware.Namespace, middleware.Spec.Errors)
 		if err != nil {
 			log.FromContext(ctxMid).Errorf("Error while reading error page middleware: %v", err)
@@ -272,6 +278,7 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			BasicAuth:         basicAuth,
 			DigestAuth:        digestAuth,
 			ForwardAuth:       forwardAuth,
+			ExternalOPA:       externalOPA,
 			InFlightReq:       middleware.Spec.InFlightReq,
 			Buffering:         middleware.Spec.Buffering,
 			CircuitBreaker:    circuitBreaker,
@@ -529,6 +536,28 @@ func createCircuitBreakerMiddleware(circuitBreaker *v1alpha1.CircuitBreaker) (*d
 	return cb, nil
 }
 
+func createExternalOPAMiddleware(externalOPA *v1alpha1.ExternalOPA) (*dynamic.ExternalOPA, error) {
+	if externalOPA == nil {
+		return nil, nil
+	}
+
+	opa := &dynamic.ExternalOPA{
+		Address:      externalOPA.Address,
+		Decision:     externalOPA.Decision,
+		PolicyFile:   externalOPA.PolicyFile,
+		InputHeaders: externalOPA.InputHeaders,
+		ReasonHeader: externalOPA.ReasonHeader,
+	}
+
+	if externalOPA.Timeout != nil {
+		if err := opa.Timeout.Set(externalOPA.Timeout.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return opa, nil
+}
+
 func createRateLimitMiddleware(rateLimit *v1alpha1.RateLimit) (*dynamic.RateLimit, error) {
 	if rateLimit == nil {
 		return nil, nil
@@ -1543,6 +1543,14 @@ func TestLoadIngressRoutes(t *testing.T) {
 						},
 					},
 					Middlewares: map[string]*dynamic.Middleware{
+						"default-externalopa": {
+							ExternalOPA: &dynamic.ExternalOPA{
+								Address:      "http://opa:8181",
+								Decision:     "traefik/authz/allow",
+								InputHeaders: []string{"Authorization"},
+								Timeout:      ptypes.Duration(5 * time.Second),
+							},
+						},
 						"default-ratelimit": {
 							RateLimit: &dynamic.RateLimit{
 								Average: 6,
@@ -41,6 +41,7 @@ type MiddlewareSpec struct {
 	BasicAuth         *BasicAuth                 `json:"basicAuth,omitempty"`
 	DigestAuth        *DigestAuth                `json:"digestAuth,omitempty"`
 	ForwardAuth       *ForwardAuth               `json:"forwardAuth,omitempty"`
+	ExternalOPA       *ExternalOPA               `json:"externalOPA,omitempty"`
 	InFlightReq       *dynamic.InFlightReq       `json:"inFlightReq,omitempty"`
 	Buffering         *dynamic.Buffering         `json:"buffering,omitempty"`
 	CircuitBreaker    *CircuitBreaker            `json:"circuitBreaker,omitempty"`
@@ -157,6 +158,30 @@ type ForwardAuth struct {
 	TLS *ClientTLS `json:"tls,omitempty"`
 }
 
+// +k8s:deepcopy-gen=true
+
+// ExternalOPA holds the ExternalOPA middleware configuration.
+// This middleware authorizes the requests by querying a decision of an external Open Policy Agent server.
+// More info: https://doc.traefik.io/traefik/v2.9/middlewares/http/externalopa/
+type ExternalOPA struct {
+	// Address defines the address of the OPA server.
+	Address string `json:"address,omitempty"`
+	// Decision defines the path of the policy decision to query (e.g. traefik/authz/allow).
+	Decision string `json:"decision,omitempty"`
+	// PolicyFile defines the path, in the Traefik container, of a Rego policy file to push to the OPA server.
+	PolicyFile string `json:"policyFile,omitempty"`
+	// InputHeaders defines the list of the request headers sent to the OPA server in the input of the decision.
+	// If not set or empty then no request header is sent.
+	InputHeaders []string `json:"inputHeaders,omitempty"`
+	// ReasonHeader defines the name of the response header conveying the reason of a denial.
+	ReasonHeader string `json:"reasonHeader,omitempty"`
+	// Timeout defines the timeout of the requests to the OPA server.
+	// The value should be provided in seconds or as a valid duration format,
+	// see https://pkg.go.dev/time#ParseDuration.
+	// Default: 30s.
+	Timeout *intstr.IntOrString `json:"timeout,omitempty"`
+}
+
 // ClientTLS holds the client TLS configuration.
 type ClientTLS struct {
 	// CASecret is the name of the referenced Kubernetes Secret containing the CA to validate the server certificate.
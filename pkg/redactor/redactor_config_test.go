@@ -870,7 +870,8 @@ func TestDo_staticConfiguration(t *testing.T) {
 				},
 			},
 		},
-		BufferingSize: 42,
+		BufferingSize:     42,
+		SampledTracesOnly: true,
 	}
 
 	config.Tracing = &static.Tracing{
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/containous/alice"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/middlewares/addprefix"
 	"github.com/traefik/traefik/v2/pkg/middlewares/auth"
@@ -42,8 +44,14 @@ const (
 // Builder the middleware builder.
 type Builder struct {
 	configs        map[string]*runtime.MiddlewareInfo
+	services       map[string]*runtime.ServiceInfo
 	pluginBuilder  PluginsBuilder
 	serviceBuilder serviceBuilder
+
+	// policyWatches are the names of the ExternalOPA middlewares whose policy is watched,
+	// so that it is pushed once per middleware definition, whatever the number of routers using it.
+	policyWatchesMu sync.Mutex
+	policyWatches   map[string]struct{}
 }
 
 type serviceBuilder interface {
@@ -51,8 +59,14 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, services map[string]*runtime.ServiceInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder) *Builder {
+	return &Builder{
+		configs:        configs,
+		services:       services,
+		serviceBuilder: serviceBuilder,
+		pluginBuilder:  pluginBuilder,
+		policyWatches:  make(map[string]struct{}),
+	}
 }
 
 // BuildChain creates a middleware chain.
@@ -91,6 +105,20 @@ func (b *Builder) BuildChain(ctx context.Context, middlewares []string) *alice.C
 	return &chain
 }
 
+// watchExternalOPAPolicy starts the watch of the policy of the given ExternalOPA middleware,
+// unless it is already watched for another router.
+func (b *Builder) watchExternalOPAPolicy(ctx context.Context, config dynamic.ExternalOPA, middlewareName string) {
+	b.policyWatchesMu.Lock()
+	defer b.policyWatchesMu.Unlock()
+
+	if _, ok := b.policyWatches[middlewareName]; ok {
+		return
+	}
+	b.policyWatches[middlewareName] = struct{}{}
+
+	auth.WatchExternalOPAPolicy(ctx, config, middlewareName)
+}
+
 func checkRecursion(ctx context.Context, middlewareName string) (context.Context, error) {
 	currentStack, ok := ctx.Value(middlewareStackKey).([]string)
 	if !ok {
@@ -160,8 +188,22 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		if middleware != nil {
 			return nil, badConf
 		}
+		var serviceStatus circuitbreaker.ServiceStatus
+		if config.CircuitBreaker.HealthCheckService != "" {
+			serviceName := provider.GetQualifiedName(ctx, config.CircuitBreaker.HealthCheckService)
+			serviceInfo, ok := b.services[serviceName]
+			if !ok {
+				return nil, fmt.Errorf("the service %q does not exist", serviceName)
+			}
+			// Only the health check of a load-balancer service reports its servers as down, which would otherwise never gate the recovery.
+			if serviceInfo.Service == nil || serviceInfo.LoadBalancer == nil || serviceInfo.LoadBalancer.HealthCheck == nil {
+				return nil, fmt.Errorf("the service %q is not a load-balancer service with a health check", serviceName)
+			}
+			serviceStatus = serviceInfo
+		}
+
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, middlewareName)
+			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, serviceStatus, middlewareName)
 		}
 	}
 
@@ -220,6 +262,22 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// ExternalOPA
+	if config.ExternalOPA != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			handler, err := auth.NewExternalOPA(ctx, next, *config.ExternalOPA, middlewareName)
+			if err != nil {
+				return nil, err
+			}
+
+			b.watchExternalOPAPolicy(ctx, *config.ExternalOPA, middlewareName)
+			return handler, nil
+		}
+	}
+
 	// GrpcWeb
 	if config.GrpcWeb != nil {
 		if middleware != nil {
@@ -5,7 +5,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +22,7 @@ func TestBuilder_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -29,7 +33,7 @@ func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"foobar": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -270,7 +274,7 @@ func TestBuilder_BuildChainWithContext(t *testing.T) {
 					Middlewares: test.configuration,
 				},
 			})
-			builder := NewBuilder(rtConf.Middlewares, nil, nil)
+			builder := NewBuilder(rtConf.Middlewares, rtConf.Services, nil, nil)
 
 			result := builder.BuildChain(ctx, test.buildChain)
 
@@ -329,7 +333,7 @@ func TestBuilder_buildConstructor(t *testing.T) {
 			Middlewares: testConfig,
 		},
 	})
-	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil)
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, rtConf.Services, nil, nil)
 
 	testCases := []struct {
 		desc          string
@@ -382,3 +386,135 @@ func TestBuilder_buildConstructor(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_buildConstructor_circuitBreakerHealthCheckService(t *testing.T) {
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"cb-checked": {
+					CircuitBreaker: &dynamic.CircuitBreaker{
+						Expression:         "NetworkErrorRatio() > 0.5",
+						HealthCheckService: "checked",
+					},
+				},
+				"cb-unchecked": {
+					CircuitBreaker: &dynamic.CircuitBreaker{
+						Expression:         "NetworkErrorRatio() > 0.5",
+						HealthCheckService: "unchecked",
+					},
+				},
+				"cb-weighted": {
+					CircuitBreaker: &dynamic.CircuitBreaker{
+						Expression:         "NetworkErrorRatio() > 0.5",
+						HealthCheckService: "weighted",
+					},
+				},
+				"cb-missing": {
+					CircuitBreaker: &dynamic.CircuitBreaker{
+						Expression:         "NetworkErrorRatio() > 0.5",
+						HealthCheckService: "missing",
+					},
+				},
+			},
+			Services: map[string]*dynamic.Service{
+				"checked": {
+					LoadBalancer: &dynamic.ServersLoadBalancer{
+						HealthCheck: &dynamic.ServerHealthCheck{Path: "/health"},
+					},
+				},
+				"unchecked": {
+					LoadBalancer: &dynamic.ServersLoadBalancer{},
+				},
+				"weighted": {
+					Weighted: &dynamic.WeightedRoundRobin{
+						HealthCheck: &dynamic.HealthCheck{},
+					},
+				},
+			},
+		},
+	})
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, rtConf.Services, nil, nil)
+
+	testCases := []struct {
+		desc          string
+		middlewareID  string
+		expectedError string
+	}{
+		{
+			desc:         "Should create a circuit breaker gated on a load-balancer service with a health check",
+			middlewareID: "cb-checked",
+		},
+		{
+			desc:          "Should not create a circuit breaker gated on a service without health check",
+			middlewareID:  "cb-unchecked",
+			expectedError: `the service "unchecked" is not a load-balancer service with a health check`,
+		},
+		{
+			desc:          "Should not create a circuit breaker gated on a weighted service",
+			middlewareID:  "cb-weighted",
+			expectedError: `the service "weighted" is not a load-balancer service with a health check`,
+		},
+		{
+			desc:          "Should not create a circuit breaker gated on a missing service",
+			middlewareID:  "cb-missing",
+			expectedError: `the service "missing" does not exist`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := middlewaresBuilder.buildConstructor(context.Background(), test.middlewareID)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuilder_BuildChain_externalOPAPolicyPushedOnce(t *testing.T) {
+	var pushes int32
+	opaServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			atomic.AddInt32(&pushes, 1)
+		}
+	}))
+	t.Cleanup(opaServer.Close)
+
+	policyFile := filepath.Join(t.TempDir(), "policy.rego")
+	require.NoError(t, os.WriteFile(policyFile, []byte("package allow\ndefault allow = true"), 0o600))
+
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"opa": {
+					ExternalOPA: &dynamic.ExternalOPA{
+						Address:    opaServer.URL,
+						Decision:   "allow",
+						PolicyFile: policyFile,
+					},
+				},
+			},
+		},
+	})
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, rtConf.Services, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// The middleware is used by two routers.
+	for i := 0; i < 2; i++ {
+		_, err := middlewaresBuilder.BuildChain(ctx, []string{"opa"}).Then(http.NotFoundHandler())
+		require.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&pushes) == 1 }, time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&pushes))
+}
@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/traefik/traefik/v2/pkg/healthcheck"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/metrics"
 	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
@@ -27,11 +28,13 @@ type Server struct {
 	stopChan chan bool
 
 	routinesPool *safe.Pool
+
+	healthCheck *healthcheck.HealthCheck
 }
 
 // NewServer returns an initialized Server.
 func NewServer(routinesPool *safe.Pool, entryPoints TCPEntryPoints, entryPointsUDP UDPEntryPoints, watcher *ConfigurationWatcher,
-	chainBuilder *middleware.ChainBuilder, accessLoggerMiddleware *accesslog.Handler,
+	chainBuilder *middleware.ChainBuilder, accessLoggerMiddleware *accesslog.Handler, healthCheck *healthcheck.HealthCheck,
 ) *Server {
 	srv := &Server{
 		watcher:                watcher,
@@ -42,6 +45,7 @@ func NewServer(routinesPool *safe.Pool, entryPoints TCPEntryPoints, entryPointsU
 		stopChan:               make(chan bool, 1),
 		routinesPool:           routinesPool,
 		udpEntryPoints:         entryPointsUDP,
+		healthCheck:            healthCheck,
 	}
 
 	srv.configureSignals()
@@ -78,6 +82,13 @@ func (s *Server) Stop() {
 	s.tcpEntryPoints.Stop()
 	s.udpEntryPoints.Stop()
 
+	// The health checks are stopped once the entry points do not forward requests to the checked servers anymore.
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheck.StopTimeout)
+	defer cancel()
+	if err := s.healthCheck.Stop(ctx); err != nil {
+		log.WithoutContext().Errorf("Could not stop the health checks: %v", err)
+	}
+
 	s.stopChan <- true
 }
 
@@ -51,6 +51,8 @@ func NewManager(configs map[string]*runtime.ServiceInfo, metricsRegistry metrics
 		bufferPool:          newBufferPool(),
 		roundTripperManager: roundTripperManager,
 		balancers:           make(map[string]healthcheck.Balancers),
+		syncBalancers:       make(map[string]*healthcheck.SyncBalancers),
+		warmedUp:            make(map[string]struct{}),
 		configs:             configs,
 		rand:                rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
@@ -67,8 +69,15 @@ type Manager struct {
 	// (e.g. if 2 routers refer to the same service name, 2 service handlers are created),
 	// which is why there is not just one Balancer per service name.
 	balancers map[string]healthcheck.Balancers
-	configs   map[string]*runtime.ServiceInfo
-	rand      *rand.Rand // For the initial shuffling of load-balancers.
+	// syncBalancers holds, keyed by service name, the sets of Balancers updated by the health checks.
+	// They are shared by the managers built by the same factory, which swap the Balancers of the sets on reconfiguration,
+	// once the health checks of the former configuration are stopped, so that the former Balancers are never updated afterwards.
+	// Only used by LaunchHealthCheck, which runs once per configuration.
+	syncBalancers map[string]*healthcheck.SyncBalancers
+	// warmedUp is the set of the services whose connections have been warmed up.
+	warmedUp map[string]struct{}
+	configs  map[string]*runtime.ServiceInfo
+	rand     *rand.Rand // For the initial shuffling of load-balancers.
 }
 
 // BuildHTTP Creates a http.Handler for a service configuration.
@@ -291,26 +300,71 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 }
 
 // LaunchHealthCheck launches the health checks.
-func (m *Manager) LaunchHealthCheck() {
+// The given context is done once the configuration is replaced, which stops the warm-up of its connections.
+func (m *Manager) LaunchHealthCheck(ctx context.Context) {
+	hc := healthcheck.GetHealthCheck(m.metricsRegistry)
+
 	backendConfigs := make(map[string]*healthcheck.BackendConfig)
 
 	for serviceName, balancers := range m.balancers {
-		ctx := log.With(context.Background(), log.Str(log.ServiceName, serviceName))
+		ctx := log.With(ctx, log.Str(log.ServiceName, serviceName))
 
 		service := m.configs[serviceName].LoadBalancer
 
+		if service.WarmUpConnections > 0 && (service.HealthCheck == nil || service.HealthCheck.Path == "") {
+			log.FromContext(ctx).Warnf("Ignoring the warm-up of the connections of service %s: no health check path provided", serviceName)
+		}
+
 		// Health Check
 		hcOpts := buildHealthCheckOptions(ctx, balancers, serviceName, service.HealthCheck)
 		if hcOpts == nil {
 			continue
 		}
+		hcOpts.LB = m.newSyncBalancers(serviceName, balancers)
 		hcOpts.Transport, _ = m.roundTripperManager.Get(service.ServersTransport)
 		log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
-		backendConfigs[serviceName] = healthcheck.NewBackendConfig(*hcOpts, serviceName)
+		backendConfig, err := healthcheck.NewBackendConfig(*hcOpts, serviceName)
+		if err != nil {
+			log.FromContext(ctx).Errorf("Ignoring health check configuration for service %s: %v", serviceName, err)
+			continue
+		}
+
+		backendConfigs[serviceName] = backendConfig
+
+		// The connections are pooled by the round tripper, which is shared by all the handlers of the service.
+		if _, ok := m.warmedUp[serviceName]; !ok && service.WarmUpConnections > 0 && service.HealthCheck.Path != "" {
+			m.warmedUp[serviceName] = struct{}{}
+
+			roundTripper, info := hcOpts.Transport, m.configs[serviceName]
+			safe.Go(func() {
+				<-backendConfig.Checked()
+				warmUp(ctx, roundTripper, service, backendConfig, info)
+			})
+		}
+	}
+
+	// The sets of the services without health check anymore are not updated by anything.
+	for serviceName, set := range m.syncBalancers {
+		if _, ok := backendConfigs[serviceName]; !ok {
+			set.Close()
+			delete(m.syncBalancers, serviceName)
+		}
+	}
+
+	hc.SetBackendsConfiguration(context.Background(), backendConfigs)
+}
+
+// newSyncBalancers returns a new set of the given Balancers for the given service, and closes its former set, if any,
+// so that the checks of the former configuration which did not return yet, e.g. when stopping them timed out, never update the Balancers anymore.
+func (m *Manager) newSyncBalancers(serviceName string, balancers healthcheck.Balancers) *healthcheck.SyncBalancers {
+	if former, ok := m.syncBalancers[serviceName]; ok {
+		former.Close()
 	}
 
-	healthcheck.GetHealthCheck(m.metricsRegistry).SetBackendsConfiguration(context.Background(), backendConfigs)
+	set := healthcheck.NewSyncBalancers(balancers...)
+	m.syncBalancers[serviceName] = set
+	return set
 }
 
 func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backend string, hc *dynamic.ServerHealthCheck) *healthcheck.Options {
@@ -320,7 +374,7 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backe
 
 	logger := log.FromContext(ctx)
 
-	if hc.Path == "" {
+	if hc.Path == "" && hc.Mode != healthcheck.TCPMode && hc.Mode != healthcheck.UDPMode && hc.Mode != healthcheck.DNSMode {
 		logger.Errorf("Ignoring heath check configuration for '%s': no path provided", backend)
 		return nil
 	}
@@ -355,6 +409,18 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backe
 		logger.Warnf("Health check timeout for backend '%s' should be lower than the health check interval. Interval set to timeout + 1 second (%s).", backend, interval)
 	}
 
+	drainDuration := time.Duration(hc.DrainDuration)
+	if drainDuration < 0 {
+		logger.Errorf("Health check drain duration smaller than zero for backend '%s'", backend)
+		drainDuration = 0
+	}
+
+	passiveWindow, passiveErrorRatio := hc.PassiveWindow, hc.PassiveErrorRatio
+	if (passiveWindow != 0 || passiveErrorRatio != 0) && (passiveWindow <= 0 || passiveErrorRatio <= 0 || passiveErrorRatio >= 1) {
+		logger.Errorf("Ignoring the passive health check of backend '%s': the window must be positive, and the error ratio between 0 and 1", backend)
+		passiveWindow, passiveErrorRatio = 0, 0
+	}
+
 	followRedirects := true
 	if hc.FollowRedirects != nil {
 		followRedirects = *hc.FollowRedirects
@@ -364,24 +430,27 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backe
 	switch hc.Mode {
 	case "":
 		mode = healthcheck.HTTPMode
-	case healthcheck.GRPCMode, healthcheck.HTTPMode:
+	case healthcheck.GRPCMode, healthcheck.HTTPMode, healthcheck.TCPMode, healthcheck.UDPMode, healthcheck.DNSMode, healthcheck.WSMode:
 		mode = hc.Mode
 	default:
 		logger.Errorf("Illegal health check mode for backend '%s'", backend)
 	}
 
 	return &healthcheck.Options{
-		Scheme:          hc.Scheme,
-		Mode:            mode,
-		Path:            hc.Path,
-		Method:          hc.Method,
-		Port:            hc.Port,
-		Interval:        interval,
-		Timeout:         timeout,
-		LB:              lb,
-		Hostname:        hc.Hostname,
-		Headers:         hc.Headers,
-		FollowRedirects: followRedirects,
+		Scheme:            hc.Scheme,
+		Mode:              mode,
+		Path:              hc.Path,
+		Method:            hc.Method,
+		Port:              hc.Port,
+		Interval:          interval,
+		Timeout:           timeout,
+		LB:                lb,
+		Hostname:          hc.Hostname,
+		Headers:           hc.Headers,
+		FollowRedirects:   followRedirects,
+		DrainDuration:     drainDuration,
+		PassiveWindow:     passiveWindow,
+		PassiveErrorRatio: passiveErrorRatio,
 	}
 }
 
@@ -412,12 +481,29 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 		logger.Debugf("Sticky session cookie name: %v", cookieName)
 	}
 
+	// The outcomes of the requests are reported downstream of the load-balancer, which sets the URL of the requests to the one of the selected server,
+	// to the passive health check of the service.
+	if hc := service.HealthCheck; hc != nil && hc.PassiveWindow > 0 && hc.PassiveErrorRatio > 0 {
+		fwd = healthcheck.NewPassiveReporter(fwd, healthcheck.GetHealthCheck(m.metricsRegistry), serviceName)
+	}
+
+	// The in-flight requests are counted downstream of the load-balancer, which sets the URL of the requests to the one of the selected server,
+	// so that the servers failing their checks can be drained.
+	var inFlight *healthcheck.InFlightCounter
+	if service.HealthCheck != nil && service.HealthCheck.DrainDuration > 0 {
+		inFlight = healthcheck.NewInFlightCounter(fwd)
+		fwd = inFlight
+	}
+
 	lb, err := roundrobin.New(fwd, options...)
 	if err != nil {
 		return nil, err
 	}
 
 	lbsu := healthcheck.NewLBStatusUpdater(lb, m.configs[serviceName], service.HealthCheck)
+	if inFlight != nil {
+		lbsu.SetInFlightCounter(inFlight)
+	}
 	if err := m.upsertServers(ctx, lbsu, service.Servers); err != nil {
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %w", serviceName, err)
 	}
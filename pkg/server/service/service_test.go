@@ -2,15 +2,23 @@ package service
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/healthcheck"
+	"github.com/traefik/traefik/v2/pkg/metrics"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	"github.com/traefik/traefik/v2/pkg/testhelpers"
 )
@@ -79,6 +87,92 @@ func TestGetLoadBalancer(t *testing.T) {
 	}
 }
 
+func TestGetLoadBalancer_drainDuration(t *testing.T) {
+	const serverURL = "http://127.0.0.1:8080"
+
+	info := &runtime.ServiceInfo{}
+	sm := Manager{configs: map[string]*runtime.ServiceInfo{"test": info}}
+
+	served := make(chan struct{})
+	release := make(chan struct{})
+	fwd := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(served)
+		<-release
+	})
+
+	handler, err := sm.getLoadBalancer(context.Background(), "test", &dynamic.ServersLoadBalancer{
+		Servers:     []dynamic.Server{{URL: serverURL}},
+		HealthCheck: &dynamic.ServerHealthCheck{Path: "/health", DrainDuration: ptypes.Duration(time.Hour)},
+	}, fwd)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	<-served
+
+	// The server keeps draining its in-flight request, instead of being reported as down right away.
+	drainer, ok := handler.(healthcheck.Drainer)
+	require.True(t, ok)
+	require.NoError(t, drainer.DrainServer(testhelpers.MustParseURL(serverURL), time.Hour))
+	assert.Equal(t, "DRAINING", info.GetAllStatus()[serverURL])
+
+	close(release)
+	<-done
+
+	assert.Eventually(t, func() bool {
+		return info.GetAllStatus()[serverURL] == "DOWN"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGetLoadBalancer_passiveHealthCheck(t *testing.T) {
+	// The active checks keep passing, while the requests forwarded to the server fail.
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	info := &runtime.ServiceInfo{}
+	sm := Manager{
+		configs:         map[string]*runtime.ServiceInfo{"test": info},
+		metricsRegistry: metrics.NewVoidRegistry(),
+	}
+
+	fwd := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	service := &dynamic.ServersLoadBalancer{
+		Servers: []dynamic.Server{{URL: server.URL}},
+		HealthCheck: &dynamic.ServerHealthCheck{
+			Path:              "/health",
+			Interval:          "1h",
+			PassiveWindow:     2,
+			PassiveErrorRatio: 0.5,
+		},
+	}
+
+	handler, err := sm.getLoadBalancer(context.Background(), "test", service, fwd)
+	require.NoError(t, err)
+
+	backend, err := healthcheck.NewBackendConfig(*buildHealthCheckOptions(context.Background(), handler, "test", service.HealthCheck), "test")
+	require.NoError(t, err)
+
+	hc := healthcheck.GetHealthCheck(sm.metricsRegistry)
+	hc.SetBackendsConfiguration(context.Background(), map[string]*healthcheck.BackendConfig{"test": backend})
+	t.Cleanup(func() {
+		hc.SetBackendsConfiguration(context.Background(), map[string]*healthcheck.BackendConfig{})
+		require.NoError(t, hc.Stop(context.Background()))
+	})
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Empty(t, handler.Servers())
+	assert.Equal(t, "DOWN", info.GetAllStatus()[server.URL])
+}
+
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
 	sm := NewManager(nil, nil, nil, &RoundTripperManager{
 		roundTrippers: map[string]http.RoundTripper{
@@ -419,3 +513,222 @@ func TestMultipleTypeOnBuildHTTP(t *testing.T) {
 	_, err := manager.BuildHTTP(context.Background(), "test@file")
 	assert.Error(t, err, "cannot create service: multi-types service not supported, consider declaring two different pieces of service instead")
 }
+
+func TestWarmUpConnections(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		states = make(map[net.Conn]http.ConnState)
+		paths  []string
+	)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		states[conn] = state
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	// Never serving, this server fails its health check, and is not warmed up.
+	downServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+	t.Cleanup(func() { _ = downServer.Listener.Close() })
+
+	transport := &http.Transport{MaxIdleConnsPerHost: 10}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	services := map[string]*runtime.ServiceInfo{
+		"test@file": {
+			Service: &dynamic.Service{
+				LoadBalancer: &dynamic.ServersLoadBalancer{
+					Servers: []dynamic.Server{
+						{URL: server.URL},
+						{URL: "http://" + downServer.Listener.Addr().String()},
+					},
+					HealthCheck: &dynamic.ServerHealthCheck{
+						Path:     "/health",
+						Interval: "1h",
+						Timeout:  "100ms",
+					},
+					WarmUpConnections: 3,
+				},
+			},
+		},
+	}
+
+	manager := NewManager(services, metrics.NewVoidRegistry(), nil, &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": transport,
+		},
+	})
+
+	// Building the service a second time, e.g. for a second router, must not warm up the connections again.
+	for i := 0; i < 2; i++ {
+		_, err := manager.BuildHTTP(context.Background(), "test@file")
+		require.NoError(t, err)
+	}
+
+	// The connections are warmed up once the first round of health checks is over.
+	manager.LaunchHealthCheck(context.Background())
+	t.Cleanup(func() {
+		hc := healthcheck.GetHealthCheck(manager.metricsRegistry)
+		hc.SetBackendsConfiguration(context.Background(), map[string]*healthcheck.BackendConfig{})
+		require.NoError(t, hc.Stop(context.Background()))
+	})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var idle int
+		for _, state := range states {
+			if state == http.StateIdle {
+				idle++
+			}
+		}
+
+		return idle == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"GET /health", "HEAD /health", "HEAD /health", "HEAD /health"}, paths)
+}
+
+func TestWarmUp(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodHead && req.URL.Path == "/health" {
+			atomic.AddInt32(&requests, 1)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	// The server URL has another port than the one of the check, which is the one warmed up.
+	trafficURL := "http://" + net.JoinHostPort(serverURL.Hostname(), "1")
+
+	service := &dynamic.ServersLoadBalancer{
+		Servers:           []dynamic.Server{{URL: trafficURL}},
+		HealthCheck:       &dynamic.ServerHealthCheck{Path: "/health", Port: port},
+		WarmUpConnections: 2,
+	}
+
+	backend, err := healthcheck.NewBackendConfig(healthcheck.Options{Path: "/health", Port: port}, "test@file")
+	require.NoError(t, err)
+
+	info := &runtime.ServiceInfo{}
+	info.UpdateServerStatus(trafficURL, serverUp)
+
+	testCases := []struct {
+		desc             string
+		replaced         bool
+		expectedRequests int32
+	}{
+		{
+			desc:             "warm-up on the port of the check",
+			expectedRequests: 2,
+		},
+		{
+			desc:     "replaced configuration",
+			replaced: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if test.replaced {
+				cancel()
+			}
+
+			warmUp(ctx, http.DefaultTransport, service, backend, info)
+
+			assert.Equal(t, test.expectedRequests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestLaunchHealthCheck_syncBalancers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	newServices := func() map[string]*runtime.ServiceInfo {
+		return map[string]*runtime.ServiceInfo{
+			"test@file": {
+				Service: &dynamic.Service{
+					LoadBalancer: &dynamic.ServersLoadBalancer{
+						Servers: []dynamic.Server{{URL: server.URL}},
+						HealthCheck: &dynamic.ServerHealthCheck{
+							Path:     "/health",
+							Interval: "1h",
+						},
+					},
+				},
+			},
+		}
+	}
+
+	roundTripperManager := &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": http.DefaultTransport,
+		},
+	}
+
+	// The managers built by the same factory share the sets of Balancers.
+	syncBalancers := make(map[string]*healthcheck.SyncBalancers)
+	newManager := func(services map[string]*runtime.ServiceInfo) *Manager {
+		manager := NewManager(services, metrics.NewVoidRegistry(), nil, roundTripperManager)
+		manager.syncBalancers = syncBalancers
+		return manager
+	}
+
+	hc := healthcheck.GetHealthCheck(metrics.NewVoidRegistry())
+	t.Cleanup(func() {
+		hc.SetBackendsConfiguration(context.Background(), map[string]*healthcheck.BackendConfig{})
+		require.NoError(t, hc.Stop(context.Background()))
+	})
+
+	first := newManager(newServices())
+	_, err := first.BuildHTTP(context.Background(), "test@file")
+	require.NoError(t, err)
+	first.LaunchHealthCheck(context.Background())
+
+	set := syncBalancers["test@file"]
+	require.NotNil(t, set)
+	assert.Equal(t, first.balancers["test@file"], set.Balancers())
+
+	// On reconfiguration, the Balancers of the new configuration get a new set, and the former set is closed.
+	second := newManager(newServices())
+	_, err = second.BuildHTTP(context.Background(), "test@file")
+	require.NoError(t, err)
+	second.LaunchHealthCheck(context.Background())
+
+	newSet := syncBalancers["test@file"]
+	require.NotNil(t, newSet)
+	assert.NotSame(t, set, newSet)
+	assert.Equal(t, second.balancers["test@file"], newSet.Balancers())
+	assert.Empty(t, set.Balancers())
+
+	backend := hc.Backends["test@file"]
+	require.NotNil(t, backend)
+	assert.Same(t, newSet, backend.LB)
+
+	// The set of a removed service is closed and dropped.
+	newManager(map[string]*runtime.ServiceInfo{}).LaunchHealthCheck(context.Background())
+	assert.Empty(t, syncBalancers)
+	assert.Empty(t, newSet.Balancers())
+}
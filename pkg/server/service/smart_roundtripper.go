@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/healthcheck"
 	"golang.org/x/net/http/httpguts"
 	"golang.org/x/net/http2"
 )
@@ -46,6 +47,8 @@ func newSmartRoundTripper(transport *http.Transport, forwardingTimeouts *dynamic
 	}, nil
 }
 
+var _ healthcheck.TransportCloner = (*smartRoundTripper)(nil)
+
 // smartRoundTripper implements RoundTrip while making sure that HTTP/2 is not used
 // with protocols that start with a Connection Upgrade, such as SPDY or Websocket.
 type smartRoundTripper struct {
@@ -61,3 +64,9 @@ func (m *smartRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 
 	return m.http2.RoundTrip(req)
 }
+
+// CloneTransport returns a copy of the HTTP/1 transport, carrying the configuration of the ServersTransport,
+// so that the health checks can override its options.
+func (m *smartRoundTripper) CloneTransport() *http.Transport {
+	return m.http.Clone()
+}
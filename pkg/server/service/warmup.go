@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/healthcheck"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+const (
+	warmUpTimeout = 5 * time.Second
+	serverUp      = "UP"
+)
+
+// warmUp pre-establishes idle connections to each server of the given service,
+// by sending concurrent requests to the URL of the health check of the server through the given round tripper.
+// It runs once the first round of health checks is over, and skips the servers the health check did not report as up.
+// It does nothing once the given context is done, e.g. when the configuration was replaced in the meantime.
+func warmUp(ctx context.Context, roundTripper http.RoundTripper, service *dynamic.ServersLoadBalancer, backend *healthcheck.BackendConfig, info *runtime.ServiceInfo) {
+	logger := log.FromContext(ctx)
+
+	if ctx.Err() != nil {
+		logger.Debug("Skipping the warm-up of the connections of a replaced configuration")
+		return
+	}
+
+	var statuses map[string]string
+	if info != nil {
+		statuses = info.GetAllStatus()
+	}
+
+	var wg sync.WaitGroup
+	for _, server := range service.Servers {
+		if status := statuses[server.URL]; status != serverUp {
+			logger.Debugf("Skipping the warm-up of the server %s, which is not up", server.URL)
+			continue
+		}
+
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			logger.Errorf("Unable to warm up the server %s: %v", server.URL, err)
+			continue
+		}
+
+		// The target has the scheme and port of the check, hence shares its connections.
+		target, err := backend.CheckURL(u)
+		if err != nil {
+			logger.Errorf("Unable to warm up the server %s: %v", server.URL, err)
+			continue
+		}
+
+		for i := 0; i < service.WarmUpConnections; i++ {
+			wg.Add(1)
+			safe.Go(func() {
+				defer wg.Done()
+
+				if err := warmUpConnection(ctx, roundTripper, target, service.HealthCheck.Hostname); err != nil {
+					logger.Debugf("Unable to warm up a connection to the server %s: %v", target.Host, err)
+				}
+			})
+		}
+	}
+
+	wg.Wait()
+}
+
+// warmUpConnection sends a request to the target,
+// which leaves its connection idle in the pool of the round tripper once the response is read.
+func warmUpConnection(ctx context.Context, roundTripper http.RoundTripper, target *url.URL, hostname string) error {
+	ctx, cancel := context.WithTimeout(ctx, warmUpTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target.String(), http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	if hostname != "" {
+		req.Host = hostname
+	}
+
+	resp, err := roundTripper.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if err := resp.Body.Close(); err != nil {
+		return fmt.Errorf("closing response body: %w", err)
+	}
+
+	return nil
+}
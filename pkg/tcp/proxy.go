@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -18,11 +19,13 @@ type Proxy struct {
 	address          string
 	tcpAddr          *net.TCPAddr
 	terminationDelay time.Duration
+	idleTimeout      time.Duration
 	proxyProtocol    *dynamic.ProxyProtocol
 }
 
 // NewProxy creates a new Proxy.
-func NewProxy(address string, terminationDelay time.Duration, proxyProtocol *dynamic.ProxyProtocol) (*Proxy, error) {
+// A positive idleTimeout closes the connections on which no data is transferred for that long.
+func NewProxy(address string, terminationDelay, idleTimeout time.Duration, proxyProtocol *dynamic.ProxyProtocol) (*Proxy, error) {
 	if proxyProtocol != nil && (proxyProtocol.Version < 1 || proxyProtocol.Version > 2) {
 		return nil, fmt.Errorf("unknown proxyProtocol version: %d", proxyProtocol.Version)
 	}
@@ -42,6 +45,7 @@ func NewProxy(address string, terminationDelay time.Duration, proxyProtocol *dyn
 		address:          address,
 		tcpAddr:          tcpAddr,
 		terminationDelay: terminationDelay,
+		idleTimeout:      idleTimeout,
 		proxyProtocol:    proxyProtocol,
 	}, nil
 }
@@ -71,15 +75,25 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 		}
 	}
 
-	go p.connCopy(conn, connBackend, errChan)
-	go p.connCopy(connBackend, conn, errChan)
+	var activity *activityTracker
+	if p.idleTimeout > 0 {
+		activity = newActivityTracker()
+
+		done := make(chan struct{})
+		defer close(done)
+
+		go p.closeWhenIdle(activity, done, conn, connBackend)
+	}
+
+	go p.connCopy(conn, connBackend, activity, errChan)
+	go p.connCopy(connBackend, conn, activity, errChan)
 
 	err = <-errChan
 	if err != nil {
 		// Treat connection reset error during a read operation with a lower log level.
 		// This allows to not report an RST packet sent by the peer as an error,
 		// as it is an abrupt but possible end for the TCP session
-		if isReadConnResetError(err) {
+		if isReadConnResetError(err) || activity.timedOut() {
 			log.WithoutContext().Debugf("Error during connection: %v", err)
 		} else {
 			log.WithoutContext().Errorf("Error during connection: %v", err)
@@ -106,8 +120,37 @@ func (p Proxy) dialBackend() (*net.TCPConn, error) {
 	return conn.(*net.TCPConn), nil
 }
 
-func (p Proxy) connCopy(dst, src WriteCloser, errCh chan error) {
-	_, err := io.Copy(dst, src)
+// closeWhenIdle closes the connections once no data has been transferred
+// for longer than the idle timeout, unless done is closed first.
+func (p Proxy) closeWhenIdle(activity *activityTracker, done <-chan struct{}, conns ...io.Closer) {
+	timer := time.NewTimer(p.idleTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+			idle := activity.idle()
+			if idle < p.idleTimeout {
+				timer.Reset(p.idleTimeout - idle)
+				continue
+			}
+
+			log.WithoutContext().Debugf("Closing connection to %s idle for %s", p.address, idle)
+
+			atomic.StoreInt32(&activity.expired, 1)
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+
+			return
+		}
+	}
+}
+
+func (p Proxy) connCopy(dst, src WriteCloser, activity *activityTracker, errCh chan error) {
+	_, err := io.Copy(dst, copySource(src, activity))
 	errCh <- err
 
 	// Ends the connection with the dst connection peer.
@@ -138,3 +181,51 @@ func isSocketNotConnectedError(err error) bool {
 	var oerr *net.OpError
 	return errors.As(err, &oerr) && errors.Is(err, syscall.ENOTCONN)
 }
+
+// activityTracker records when data was last transferred on a proxied connection.
+type activityTracker struct {
+	last    int64
+	expired int32
+}
+
+func newActivityTracker() *activityTracker {
+	return &activityTracker{last: time.Now().UnixNano()}
+}
+
+func (a *activityTracker) touch() {
+	atomic.StoreInt64(&a.last, time.Now().UnixNano())
+}
+
+func (a *activityTracker) idle() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&a.last)))
+}
+
+// timedOut reports whether the connection was closed because of the idle timeout.
+func (a *activityTracker) timedOut() bool {
+	return a != nil && atomic.LoadInt32(&a.expired) == 1
+}
+
+// copySource returns the reader to copy from src.
+// Without an activity to record, it is src itself, so that io.Copy can use
+// the ReadFrom of the TCP connections (e.g. splice on Linux) between the peers.
+func copySource(src io.Reader, activity *activityTracker) io.Reader {
+	if activity == nil {
+		return src
+	}
+
+	return activityReader{Reader: src, activity: activity}
+}
+
+// activityReader is a reader recording the activity of the reads.
+type activityReader struct {
+	io.Reader
+	activity *activityTracker
+}
+
+func (r activityReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.activity.touch()
+	}
+	return n, err
+}
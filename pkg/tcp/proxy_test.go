@@ -54,7 +54,7 @@ func TestCloseWrite(t *testing.T) {
 	_, port, err := net.SplitHostPort(backendListener.Addr().String())
 	require.NoError(t, err)
 
-	proxy, err := NewProxy(":"+port, 10*time.Millisecond, nil)
+	proxy, err := NewProxy(":"+port, 10*time.Millisecond, 0, nil)
 	require.NoError(t, err)
 
 	proxyListener, err := net.Listen("tcp", ":0")
@@ -133,7 +133,7 @@ func TestProxyProtocol(t *testing.T) {
 			_, port, err := net.SplitHostPort(proxyBackendListener.Addr().String())
 			require.NoError(t, err)
 
-			proxy, err := NewProxy(":"+port, 10*time.Millisecond, &dynamic.ProxyProtocol{Version: test.version})
+			proxy, err := NewProxy(":"+port, 10*time.Millisecond, 0, &dynamic.ProxyProtocol{Version: test.version})
 			require.NoError(t, err)
 
 			proxyListener, err := net.Listen("tcp", ":0")
@@ -198,7 +198,7 @@ func TestLookupAddress(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			proxy, err := NewProxy(test.address, 10*time.Millisecond, nil)
+			proxy, err := NewProxy(test.address, 10*time.Millisecond, 0, nil)
 			require.NoError(t, err)
 
 			test.expectRefresh(t, proxy.tcpAddr)
@@ -210,3 +210,83 @@ func TestLookupAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestCopySource(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// Without an idle timeout, the TCP connection is not hidden from io.Copy.
+	assert.Same(t, conn, copySource(conn, nil))
+
+	src := copySource(conn, newActivityTracker())
+	assert.IsType(t, activityReader{}, src)
+}
+
+func TestIdleTimeout(t *testing.T) {
+	backendListener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backendListener.Close() })
+
+	go func() {
+		for {
+			conn, err := backendListener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	proxy, err := NewProxy(backendListener.Addr().String(), 10*time.Millisecond, 100*time.Millisecond, nil)
+	require.NoError(t, err)
+
+	proxyListener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = proxyListener.Close() })
+
+	go func() {
+		for {
+			conn, err := proxyListener.Accept()
+			if err != nil {
+				return
+			}
+			go proxy.ServeTCP(conn.(*net.TCPConn))
+		}
+	}()
+
+	conn, err := net.Dial("tcp", proxyListener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// Activity keeps the connection open beyond the idle timeout.
+	start := time.Now()
+	buf := make([]byte, 4)
+	for time.Since(start) < 300*time.Millisecond {
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf))
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Once idle, the connection gets closed.
+	idleStart := time.Now()
+	err = conn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, err)
+
+	_, err = conn.Read(buf)
+	require.ErrorIs(t, err, io.EOF)
+	assert.GreaterOrEqual(t, time.Since(idleStart), 80*time.Millisecond)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	zipkinot "github.com/openzipkin-contrib/zipkin-go-opentracing"
 	"github.com/traefik/traefik/v2/pkg/log"
 )
 
@@ -128,6 +129,24 @@ func GetSpan(r *http.Request) opentracing.Span {
 	return opentracing.SpanFromContext(r.Context())
 }
 
+// IsSampled returns whether the trace of the given span is sampled.
+// The spans of the tracers which do not expose their sampling decision are considered sampled.
+func IsSampled(span opentracing.Span) bool {
+	if span == nil {
+		return false
+	}
+
+	switch spanCtx := span.Context().(type) {
+	case interface{ IsSampled() bool }:
+		// Jaeger.
+		return spanCtx.IsSampled()
+	case zipkinot.SpanContext:
+		return spanCtx.Sampled != nil && *spanCtx.Sampled
+	default:
+		return true
+	}
+}
+
 // InjectRequestHeaders used to inject OpenTracing headers into the request.
 func InjectRequestHeaders(r *http.Request) {
 	if span := GetSpan(r); span != nil {